@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/gc"
+	"github.com/DeBankDeFi/nodex/pkg/lib/log"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/DeBankDeFi/nodex/pkg/cmdhelper"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var gcFlag types.GCFlag
+
+func gcCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "remove the block objects in s3 no reader or snapshot needs any more",
+		Run:   gcRun,
+	}
+	cmdhelper.ResolveFlagVariable(cmd, &gcFlag)
+	return cmd
+}
+
+func gcRun(cmd *cobra.Command, args []string) {
+	client, err := s3.NewClient(gcFlag.S3ProxyAddr)
+	if err != nil {
+		log.Fatal("did not connect to s3 proxy", err)
+	}
+	conn, err := grpc.Dial(gcFlag.GrpcServer, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal("did not connect to grpc server", err)
+	}
+	defer conn.Close()
+	roles := []string{"master", "backup"}
+	if gcFlag.Roles != "" {
+		roles = strings.Split(gcFlag.Roles, ",")
+	}
+	checkpoints := []string{"default"}
+	if gcFlag.Checkpoints != "" {
+		checkpoints = strings.Split(gcFlag.Checkpoints, ",")
+	}
+	collector, err := gc.NewCollector(&gc.Config{
+		Env:         gcFlag.Env,
+		ChainId:     gcFlag.ChainID,
+		Roles:       roles,
+		KeepBlocks:  int64(gcFlag.KeepBlocks),
+		ReorgWindow: int64(gcFlag.ReorgWindow),
+		KeepWindow:  time.Duration(gcFlag.KeepWindow) * time.Second,
+		DryRun:      gcFlag.DryRun,
+		Checkpoints: checkpoints,
+	}, client, pb.NewSubscribeServiceClient(conn))
+	if err != nil {
+		log.Fatal("invalid gc config, -u must be positive and -k not negative", err)
+	}
+	if gcFlag.MetricListen != "" {
+		go func() {
+			http.Handle("/metrics", promhttp.Handler())
+			http.ListenAndServe(gcFlag.MetricListen, nil)
+		}()
+	}
+	if gcFlag.Interval > 0 {
+		collector.Run(context.Background(), time.Duration(gcFlag.Interval)*time.Second)
+		return
+	}
+	results, err := collector.RunOnce(context.Background())
+	if err != nil {
+		log.Fatal("gc failed", err)
+	}
+	for _, result := range results {
+		fmt.Printf("role %s cutoff %d removed %d files %d bytes\n", result.Role, result.Cutoff, result.RemovedFiles, result.RemovedBytes)
+	}
+}
@@ -16,6 +16,9 @@ func main() {
 	cmd.AddCommand(daemonCmd())
 	cmd.AddCommand(testingCmd())
 	cmd.AddCommand(failoverCmd())
+	cmd.AddCommand(rewindCmd())
+	cmd.AddCommand(restoreCmd())
+	cmd.AddCommand(gcCmd())
 	cmd.AddCommand(cmdhelper.Version())
 	cmd.Execute()
 }
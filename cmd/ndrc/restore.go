@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DeBankDeFi/nodex/pkg/checkpoint"
+	"github.com/DeBankDeFi/nodex/pkg/lib/log"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/types"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+
+	"github.com/DeBankDeFi/nodex/pkg/cmdhelper"
+	"github.com/spf13/cobra"
+)
+
+var restoreFlag types.RestoreFlag
+
+func restoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "rebuild the dbs of a remotedb from a chain of checkpoints",
+		Run:   restoreRun,
+	}
+	cmdhelper.ResolveFlagVariable(cmd, &restoreFlag)
+	return cmd
+}
+
+func restoreRun(cmd *cobra.Command, args []string) {
+	client, err := s3.NewClient(restoreFlag.S3ProxyAddr)
+	if err != nil {
+		log.Fatal("did not connect to s3 proxy", err)
+	}
+	dbInfos, err := utils.OpenAndReadDbInfo(restoreFlag.DBInfoPath)
+	if err != nil {
+		log.Fatal("read db info failed", err)
+	}
+	ctx := context.Background()
+	manifest := restoreFlag.Manifest
+	if manifest == "" {
+		prefix := utils.CheckpointPrefix(restoreFlag.Env, restoreFlag.ChainID, restoreFlag.Role, restoreFlag.Name)
+		manifest, err = checkpoint.LatestManifest(ctx, client, prefix)
+		if err != nil {
+			log.Fatal("latest checkpoint not found", err)
+		}
+	}
+	cacheSize := restoreFlag.CacheSize
+	if cacheSize <= 0 {
+		cacheSize = 64
+	}
+	restored, err := checkpoint.Restore(ctx, client, manifest, dbInfos, cacheSize)
+	if err != nil {
+		log.Fatal("restore failed", err)
+	}
+	fmt.Println(restored.Info.String())
+}
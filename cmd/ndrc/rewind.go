@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DeBankDeFi/nodex/pkg/lib/log"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/types"
+
+	"github.com/DeBankDeFi/nodex/pkg/cmdhelper"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var rewindFlag types.RewindFlag
+
+func rewindCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rewind",
+		Short: "make reader rewind to a block number and reapply the blocks after it",
+		Run:   rewindRun,
+	}
+	cmdhelper.ResolveFlagVariable(cmd, &rewindFlag)
+	return cmd
+}
+
+func rewindRun(cmd *cobra.Command, args []string) {
+	conn, err := grpc.Dial(rewindFlag.GrpcServer, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal("did not connect to grpc server", err)
+	}
+	defer conn.Close()
+	client := pb.NewRemoteClient(conn)
+	rsp, err := client.Rewind(context.Background(), &pb.RewindRequest{
+		BlockNum: int64(rewindFlag.BlockNum),
+	})
+	if err != nil {
+		log.Fatal("rewind failed", err)
+		return
+	}
+	fmt.Println(rsp.Info.String())
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"os/signal"
@@ -19,8 +20,13 @@ import (
 func main() {
 	config := &utils.Config{}
 	flag.StringVar(&config.RemoteListenAddr, "listen_addr", "0.0.0.0:7654", "remote server listen address")
-	flag.StringVar(&config.S3ProxyAddr, "s3proxy_addr", "127.0.0.1:8765", "s3 address")
+	flag.StringVar(&config.S3ProxyAddr, "s3proxy_addr", "127.0.0.1:8765", "comma separated s3 proxy addresses, dns://host:port uses all the ips of host")
+	flag.StringVar(&config.HeaderBus, "header_bus", "kafka", "message bus of block infos, kafka, redis or memory")
 	flag.StringVar(&config.KafkaAddr, "kafka_addr", "127.0.0.1:9092", "kafka address")
+	flag.StringVar(&config.RedisAddr, "redis_addr", "127.0.0.1:6379", "redis address of the redis header bus")
+	flag.IntVar(&config.KafkaPartition, "kafka_partition", 0, "kafka partition of the chain topic")
+	flag.StringVar(&config.KafkaGroupId, "kafka_group", "", "kafka consumer group the applied offset is committed to, empty disables it")
+	flag.IntVar(&config.KafkaOffsetMaxDiff, "kafka_offset_max_diff", 1024, "max difference of the meta db and consumer group offsets on startup")
 	flag.StringVar(&config.MetricEndpoint, "metric_address", ":10086", "metric address")
 	flag.StringVar(&config.Env, "env", "test", "env")
 	flag.StringVar(&config.ChainId, "chain_id", "eth", "chain id")
@@ -29,16 +35,43 @@ func main() {
 	flag.IntVar(&config.ReorgDeep, "reorg_deep", 128, "chain reorg deep")
 	flag.IntVar(&config.DBCacheSize, "db_cache_size", 2048, "db cache size in MB")
 	flag.StringVar(&config.NdrcAddr, "ndrc_addrs", "127.0.0.1:8089", "ndrc addrs")
+	flag.StringVar(&config.Discovery, "discovery", "kafka", "block discovery, kafka, s3 or auto to fall back to s3 listing while the header bus is unavailable")
+	flag.IntVar(&config.UndoWindow, "undo_window", 128, "number of latest blocks that can be rewound")
+	flag.BoolVar(&config.WatchBlocks, "watch_blocks", false, "apply the blocks pushed by the s3 proxies as they are put instead of downloading them")
+	flag.StringVar(&config.TrustedKeys, "trusted_keys", "", "comma separated base64 ed25519 public keys of the writers, unsigned or mis-signed blocks are rejected if set")
+	flag.IntVar(&config.PrefetchDepth, "prefetch_depth", 16, "number of blocks downloaded ahead of application")
+	flag.IntVar(&config.CheckpointInterval, "checkpoint_interval", 0, "seconds between incremental checkpoints of the dbs exported to s3, 0 disables them")
+	flag.StringVar(&config.CheckpointName, "checkpoint_name", "default", "name of the checkpoints of the remotedb")
+	bootstrap := flag.Bool("bootstrap", false, "install the latest snapshot of each chain at its empty db paths before starting, dbs which already hold a block are kept")
+	chainsConfig := flag.String("chains_config", "", "json file of per chain config overrides, one reader per chain")
 	flag.Parse()
 	stopChan := make(chan os.Signal, 1)
 
 	signal.Notify(stopChan, syscall.SIGTERM, syscall.SIGINT)
 
-	pool := db.NewDBPool()
+	configs := []*utils.Config{config}
+	if *chainsConfig != "" {
+		var err error
+		configs, err = utils.LoadChainConfigs(config, *chainsConfig)
+		if err != nil {
+			panic(err)
+		}
+	}
 
-	reader, err := reader.NewReader(config, pool)
-	if err != nil {
-		panic(err)
+	readers := make([]*reader.Reader, 0, len(configs))
+	for _, config := range configs {
+		if *bootstrap {
+			_, err := reader.Bootstrap(context.Background(), config)
+			if err != nil {
+				panic(err)
+			}
+		}
+		pool := db.NewDBPool()
+		reader, err := reader.NewReader(config, pool)
+		if err != nil {
+			panic(err)
+		}
+		readers = append(readers, reader)
 	}
 
 	go func() {
@@ -54,13 +87,17 @@ func main() {
 		http.ListenAndServe(config.MetricEndpoint, nil)
 	}()
 
-	err = reader.Start()
-	if err != nil {
-		panic(err)
+	for _, reader := range readers {
+		err := reader.Start()
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	defer func() {
-		reader.Stop()
+		for _, reader := range readers {
+			reader.Stop()
+		}
 	}()
 	<-stopChan
 }
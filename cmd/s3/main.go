@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"net/http"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -13,10 +15,35 @@ func main() {
 	var addr string
 	var prometheusAddr string
 	var cacheSize int
+	var cacheMemory int64
+	var diskCacheSize int64
+	var partSize int
+	var keyringPath string
+	var rekey bool
+	var rekeyPrefix string
+	storeConfig := &s3.StoreConfig{}
 	flag.StringVar(&addr, "listen_addr", "0.0.0.0:8765", "listen address")
 	flag.StringVar(&prometheusAddr, "metric_address", ":10086", "metric address")
-	flag.IntVar(&cacheSize, "cache_size", 32, "cache size")
+	flag.IntVar(&cacheSize, "cache_size", 32, "max cached blocks of each chain, the lowest heights are evicted first")
+	flag.Int64Var(&cacheMemory, "cache_memory", 1024, "memory budget of the block cache in MB, the least recently used blocks are evicted first")
+	flag.StringVar(&s3.DiskCacheDir, "disk_cache_dir", "", "directory of the on-disk block cache, encrypted with -keyring, empty disables it")
+	flag.Int64Var(&diskCacheSize, "disk_cache_size", 10240, "disk budget of the on-disk block cache in MB")
+	flag.IntVar(&partSize, "part_size", 16, "part size of multipart uploads in MB, larger blocks are streamed to the store and not cached")
+	flag.IntVar(&s3.MaxS3Reads, "max_s3_reads", 64, "max concurrent reads of the store, more reads wait in a queue, 0 disables the limit")
+	flag.Float64Var(&s3.HedgePercentile, "hedge_percentile", 0.95, "percentile of the recent read latencies of the same kind, e.g. block type, after which a read is hedged by a second one, 0 disables hedging")
+	flag.StringVar(&keyringPath, "keyring", "", "json keyring file of the master keys encrypting objects at rest, empty disables encryption")
+	flag.BoolVar(&rekey, "rekey", false, "seal the objects under -rekey_prefix by the primary key of -keyring and exit")
+	flag.StringVar(&rekeyPrefix, "rekey_prefix", "", "key prefix of the objects to rekey")
+	flag.StringVar(&storeConfig.Type, "store", s3.StoreTypeAws, "object store type: aws, fs or memory")
+	flag.StringVar(&storeConfig.Bucket, "bucket", s3.DefaultBucketName, "s3 bucket name")
+	flag.StringVar(&storeConfig.Region, "region", s3.DefaultRegion, "s3 region")
+	flag.StringVar(&storeConfig.Endpoint, "endpoint", "", "s3 compatible endpoint, e.g. minio")
+	flag.StringVar(&storeConfig.RootDir, "root_dir", "s3data", "root directory of the fs store")
 	flag.Parse()
+	if rekey {
+		runRekey(storeConfig, keyringPath, rekeyPrefix)
+		return
+	}
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 		http.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
@@ -25,7 +52,43 @@ func main() {
 		})
 		http.ListenAndServe(prometheusAddr, nil)
 	}()
-	err := s3.ListenAndServe(addr, uint(cacheSize))
+	store, err := s3.NewBlobStore(context.Background(), storeConfig)
+	if err != nil {
+		panic(err)
+	}
+	if keyringPath != "" {
+		keyring, err := s3.LoadKeyring(keyringPath)
+		if err != nil {
+			panic(err)
+		}
+		store = s3.NewEncryptedStore(store, keyring)
+	}
+	s3.MaxCacheBytes = cacheMemory << 20
+	s3.DiskCacheBytes = diskCacheSize << 20
+	s3.PartSize = partSize << 20
+	if s3.PartSize < s3.MinPartSize {
+		s3.PartSize = s3.MinPartSize
+	}
+	err = s3.ListenAndServe(addr, uint(cacheSize), store)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func runRekey(storeConfig *s3.StoreConfig, keyringPath, prefix string) {
+	if keyringPath == "" {
+		panic("-rekey needs -keyring")
+	}
+	keyring, err := s3.LoadKeyring(keyringPath)
+	if err != nil {
+		panic(err)
+	}
+	store, err := s3.NewBlobStore(context.Background(), storeConfig)
+	if err != nil {
+		panic(err)
+	}
+	n, err := s3.NewEncryptedStore(store, keyring).Rekey(context.Background(), prefix)
+	fmt.Printf("rekeyed %d objects to key %s\n", n, keyring.Primary())
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,218 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/avast/retry-go/v4"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// Checkpointer exports checkpoints of a DBPool under a prefix, every
+// checkpoint after the first full one only has the keys changed since the
+// previous checkpoint.
+type Checkpointer struct {
+	sync.Mutex
+	pool   *db.DBPool
+	client *s3.Client
+	prefix string
+	seq    int64
+	parent *pb.Snapshot // last exported checkpoint, nil if the next one is full
+}
+
+// Prepared is a snapshot of the pool waiting for its export.
+type Prepared struct {
+	ps      *db.PoolSnapshot
+	changed map[int32][]string
+}
+
+// Info returns the BlockInfo of the last block applied to the snapshot, nil if none.
+func (p *Prepared) Info() *pb.BlockInfo {
+	return p.ps.Info
+}
+
+// NewCheckpointer makes the DBs of pool track their changes, it must be called
+// before the DBs are written.
+func NewCheckpointer(pool *db.DBPool, client *s3.Client, prefix string) *Checkpointer {
+	pool.TrackChanges()
+	return &Checkpointer{
+		pool:   pool,
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Prepare snapshots the pool, the DBs must not be written meanwhile. Every
+// Prepared must be passed to Export in order, the changes of a Prepared which
+// is not are lost and the next checkpoint is full.
+func (c *Checkpointer) Prepare() (*Prepared, error) {
+	ps, changed, err := c.pool.SnapshotChanges()
+	if err != nil {
+		return nil, err
+	}
+	return &Prepared{ps: ps, changed: changed}, nil
+}
+
+// Export uploads the chunks of prepared, then its manifest, and finally points
+// the latest checkpoint to the manifest. prepared is released.
+func (c *Checkpointer) Export(ctx context.Context, prepared *Prepared) (checkpoint *pb.Snapshot, err error) {
+	defer prepared.ps.Release()
+	c.Lock()
+	defer c.Unlock()
+	defer func() {
+		if err != nil {
+			c.parent = nil
+		}
+	}()
+	seq := time.Now().UnixNano()
+	if seq <= c.seq {
+		seq = c.seq + 1
+	}
+	checkpoint = &pb.Snapshot{
+		Key:        utils.CheckpointManifestKey(c.prefix, seq),
+		Info:       prepared.ps.Info,
+		CreateTime: time.Now().Unix(),
+	}
+	parentDBs := make(map[int32]bool)
+	if c.parent != nil && prepared.changed != nil {
+		checkpoint.Parent = c.parent.Key
+		for _, snapshotDB := range c.parent.Dbs {
+			parentDBs[snapshotDB.DbInfo.Id] = true
+		}
+	}
+	for _, dbInfo := range prepared.ps.DBs {
+		snapshotDB := &pb.SnapshotDB{DbInfo: dbInfo}
+		upload := func(chunk []byte) error {
+			key := utils.CheckpointChunkKey(c.prefix, seq, dbInfo.Id, len(snapshotDB.Chunks))
+			var stored *pb.SnapshotChunk
+			err := retry.Do(
+				func() (err error) {
+					stored, err = c.client.PutCompressedFile(ctx, key, chunk)
+					return err
+				},
+				retry.Attempts(10),
+				retry.Delay(5*time.Second),
+				retry.LastErrorOnly(true),
+				retry.Context(ctx),
+			)
+			if err != nil {
+				return err
+			}
+			snapshotDB.Chunks = append(snapshotDB.Chunks, stored)
+			return nil
+		}
+		// a DB not in the parent is exported in full.
+		if parentDBs[dbInfo.Id] {
+			err = prepared.ps.ExportKeys(dbInfo.Id, prepared.changed[dbInfo.Id], db.SnapshotChunkSize, upload)
+		} else {
+			err = prepared.ps.Export(dbInfo.Id, db.SnapshotChunkSize, upload)
+		}
+		if err != nil {
+			return nil, err
+		}
+		checkpoint.Dbs = append(checkpoint.Dbs, snapshotDB)
+	}
+	buf, err := proto.Marshal(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.PutFile(ctx, checkpoint.Key, buf)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.PutFile(ctx, utils.CheckpointLatestKey(c.prefix), []byte(checkpoint.Key))
+	if err != nil {
+		return nil, err
+	}
+	c.seq = seq
+	c.parent = checkpoint
+	return checkpoint, nil
+}
+
+// LatestManifest returns the manifest key of the latest checkpoint under prefix.
+func LatestManifest(ctx context.Context, client *s3.Client, prefix string) (string, error) {
+	key, err := client.GetFile(ctx, utils.CheckpointLatestKey(prefix))
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
+// Chain returns the checkpoints from the full one to the one of manifestKey.
+func Chain(ctx context.Context, client *s3.Client, manifestKey string) ([]*pb.Snapshot, error) {
+	var chain []*pb.Snapshot
+	seen := make(map[string]bool)
+	for key := manifestKey; key != ""; {
+		if seen[key] {
+			return nil, fmt.Errorf("checkpoint %s is its own ancestor", key)
+		}
+		seen[key] = true
+		buf, err := client.GetFile(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		checkpoint := &pb.Snapshot{}
+		if err := proto.Unmarshal(buf, checkpoint); err != nil {
+			return nil, err
+		}
+		chain = append(chain, checkpoint)
+		key = checkpoint.Parent
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// Restore rebuilds the DBs of the checkpoint of manifestKey at the paths of
+// dbInfos, DBs are matched by id and their paths must not exist or be empty.
+func Restore(ctx context.Context, client *s3.Client, manifestKey string, dbInfos *pb.DBInfoList, cacheSize int) (*pb.Snapshot, error) {
+	chain, err := Chain(ctx, client, manifestKey)
+	if err != nil {
+		return nil, err
+	}
+	last := chain[len(chain)-1]
+	utils.Logger().Info("Restore", zap.String("manifest", manifestKey), zap.Int("chain", len(chain)), zap.Any("info", last.Info))
+
+	layers := make(map[int32][]*pb.SnapshotDB)
+	for _, snapshotDB := range last.Dbs {
+		layers[snapshotDB.DbInfo.Id] = dbLayers(chain, snapshotDB.DbInfo.Id)
+	}
+	err = db.Install(dbInfos, layers, cacheSize, func(chunk *pb.SnapshotChunk) ([]byte, error) {
+		return client.GetCompressedFile(ctx, chunk)
+	})
+	if err != nil {
+		utils.Logger().Error("Restore error", zap.Error(err))
+		return nil, err
+	}
+	utils.Logger().Info("Restore success", zap.Any("info", last.Info))
+	return last, nil
+}
+
+// dbLayers returns the exports of the DB id from the checkpoints of chain
+// since it was last exported in full, which is after the last checkpoint
+// without it.
+func dbLayers(chain []*pb.Snapshot, id int32) []*pb.SnapshotDB {
+	var dbs []*pb.SnapshotDB
+	for _, checkpoint := range chain {
+		var found *pb.SnapshotDB
+		for _, snapshotDB := range checkpoint.Dbs {
+			if snapshotDB.DbInfo.Id == id {
+				found = snapshotDB
+			}
+		}
+		if found == nil {
+			dbs = nil
+			continue
+		}
+		dbs = append(dbs, found)
+	}
+	return dbs
+}
@@ -0,0 +1,96 @@
+package checkpoint
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestCheckpoint(t *testing.T) {
+	go s3.ListenAndServe("0.0.0.0:8770", 32, s3.NewMemStore())
+	client, err := s3.NewClient("0.0.0.0:8770")
+	require.NoErrorf(t, err, "NewClient error")
+	client.SetCompression(pb.BlockInfo_ZSTD)
+
+	src := db.NewDBPool()
+	for id := int32(0); id < 2; id++ {
+		ldb, err := db.NewLDB(t.TempDir(), 16)
+		require.NoErrorf(t, err, "NewLDB error")
+		require.NoError(t, ldb.Put([]byte("key"), []byte{byte(id)}))
+		require.NoError(t, ldb.Put([]byte("deleted"), []byte{byte(id)}))
+		src.Register(id, "src", "leveldb", ldb, id == 0)
+	}
+	defer src.Close()
+	prefix := utils.CheckpointPrefix("test", "256", "master", "default")
+	c := NewCheckpointer(src, client, prefix)
+	require.NoError(t, src.WriteBlockInfo(&pb.BlockInfo{ChainId: "256", BlockNum: 5, BlockHash: "5", MsgOffset: 9}))
+
+	var full *pb.Snapshot
+	require.Eventually(t, func() bool {
+		prepared, err := c.Prepare()
+		require.NoError(t, err)
+		full, err = c.Export(context.Background(), prepared)
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	require.Empty(t, full.Parent)
+
+	db1, err := src.GetDB(1)
+	require.NoError(t, err)
+	batch := db1.NewBatch()
+	require.NoError(t, batch.Put([]byte("key"), []byte("new")))
+	require.NoError(t, batch.Delete([]byte("deleted")))
+	require.NoError(t, batch.Write())
+	require.NoError(t, src.WriteBlockInfo(&pb.BlockInfo{ChainId: "256", BlockNum: 6, BlockHash: "6", MsgOffset: 10}))
+	prepared, err := c.Prepare()
+	require.NoError(t, err)
+	incremental, err := c.Export(context.Background(), prepared)
+	require.NoErrorf(t, err, "Export error")
+	require.Equal(t, full.Key, incremental.Parent)
+	latest, err := LatestManifest(context.Background(), client, prefix)
+	require.NoError(t, err)
+	require.Equal(t, incremental.Key, latest)
+
+	dir := t.TempDir()
+	dbInfos := &pb.DBInfoList{}
+	for id := int32(0); id < 2; id++ {
+		dbInfos.DbInfos = append(dbInfos.DbInfos, &pb.DBInfo{
+			Id:     id,
+			DbType: "leveldb",
+			DbPath: filepath.Join(dir, string(rune('a'+id))),
+			IsMeta: id == 0,
+		})
+	}
+	restored, err := Restore(context.Background(), client, latest, dbInfos, 16)
+	require.NoErrorf(t, err, "Restore error")
+	require.Equal(t, int64(6), restored.Info.BlockNum)
+
+	dst := db.NewDBPool()
+	for _, dbInfo := range dbInfos.DbInfos {
+		require.NoError(t, dst.Open(dbInfo, 16))
+	}
+	defer dst.Close()
+	db0, err := dst.GetDB(0)
+	require.NoError(t, err)
+	val, err := db0.Get([]byte("deleted"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0}, val)
+	db1, err = dst.GetDB(1)
+	require.NoError(t, err)
+	val, err = db1.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), val)
+	_, err = db1.Get([]byte("deleted"))
+	require.ErrorIs(t, err, leveldb.ErrNotFound)
+
+	// the db paths are not empty any more.
+	_, err = Restore(context.Background(), client, latest, dbInfos, 16)
+	require.Error(t, err)
+}
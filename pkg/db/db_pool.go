@@ -15,8 +15,22 @@ import (
 const (
 	LastBlockInfo = "rpl_last_bk"
 	DBInfo        = "rpl_db_info"
+	ApplyJournal  = "rpl_apply_journal"
+
+	TopicOffsetPrefix = "rpl_offset/"
 )
 
+// TopicOffset is the kafka offset of the last applied block read from a topic partition.
+type TopicOffset struct {
+	Topic     string
+	Partition int
+	Offset    int64
+}
+
+func topicOffsetKey(topic string, partition int) []byte {
+	return []byte(fmt.Sprintf("%s%s/%d", TopicOffsetPrefix, topic, partition))
+}
+
 type dbWrap struct {
 	id     int32
 	db     DB
@@ -30,6 +44,10 @@ type DBPool struct {
 	sync.RWMutex
 	dbs      map[int32]dbWrap
 	metaDBID int32
+	// tracking is set by TrackChanges, changesKnown once SnapshotChanges
+	// swapped out the keys changed since tracking started.
+	tracking     bool
+	changesKnown bool
 }
 
 func NewDBPool() *DBPool {
@@ -44,6 +62,9 @@ func NewDBPool() *DBPool {
 func (p *DBPool) Register(id int32, path string, dbType string, db DB, isMetaDB bool) {
 	p.Lock()
 	defer p.Unlock()
+	if p.tracking {
+		db = &trackedDB{DB: db, changes: newKeySet()}
+	}
 	p.dbs[id] = dbWrap{
 		id:     id,
 		db:     db,
@@ -73,10 +94,14 @@ func (p *DBPool) Open(dbInfo *pb.DBInfo, cacheSize int) (err error) {
 				return nil
 			}
 		}
-		db, err := NewLDB(dbInfo.DbPath, cacheSize)
+		ldb, err := NewLDB(dbInfo.DbPath, cacheSize)
 		if err != nil {
 			return err
 		}
+		var db DB = ldb
+		if p.tracking {
+			db = &trackedDB{DB: db, changes: newKeySet()}
+		}
 		p.dbs[dbInfo.Id] = dbWrap{
 			id:     dbInfo.Id,
 			db:     db,
@@ -196,8 +221,9 @@ func (p *DBPool) Marshal(batchs []BatchWithID) (batchItems []*pb.Data, err error
 	return batchItems, nil
 }
 
-// WriteBlockInfo writes header Info to metaDB.
-func (p *DBPool) WriteBlockInfo(header *pb.BlockInfo) (err error) {
+// WriteBlockInfo writes header Info and the offsets it was read from to
+// metaDB, the apply journal is cleared in the same batch.
+func (p *DBPool) WriteBlockInfo(header *pb.BlockInfo, offsets ...TopicOffset) (err error) {
 	p.RLock()
 	defer p.RUnlock()
 	if p.metaDBID == math.MinInt32 {
@@ -212,6 +238,16 @@ func (p *DBPool) WriteBlockInfo(header *pb.BlockInfo) (err error) {
 	if err != nil {
 		return err
 	}
+	err = metaBatch.Delete([]byte(ApplyJournal))
+	if err != nil {
+		return err
+	}
+	for _, offset := range offsets {
+		err = metaBatch.Put(topicOffsetKey(offset.Topic, offset.Partition), encodeOffset(offset.Offset))
+		if err != nil {
+			return err
+		}
+	}
 	err = metaBatch.Write()
 	if err != nil {
 		return err
@@ -219,6 +255,96 @@ func (p *DBPool) WriteBlockInfo(header *pb.BlockInfo) (err error) {
 	return nil
 }
 
+// WriteTopicOffset writes the offset of a topic partition to metaDB.
+func (p *DBPool) WriteTopicOffset(offset TopicOffset) (err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return utils.ErrNoMetaDBRegistered
+	}
+	return p.dbs[p.metaDBID].db.Put(topicOffsetKey(offset.Topic, offset.Partition), encodeOffset(offset.Offset))
+}
+
+// GetTopicOffset returns the offset of a topic partition from metaDB, ok is
+// false if none was written.
+func (p *DBPool) GetTopicOffset(topic string, partition int) (offset int64, ok bool, err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return -1, false, utils.ErrNoMetaDBRegistered
+	}
+	buf, err := p.dbs[p.metaDBID].db.Get(topicOffsetKey(topic, partition))
+	if err == leveldb.ErrNotFound {
+		return -1, false, nil
+	}
+	if err != nil {
+		return -1, false, err
+	}
+	if len(buf) != 8 {
+		return -1, false, fmt.Errorf("invalid offset of topic %s partition %d", topic, partition)
+	}
+	return int64(binary.BigEndian.Uint64(buf)), true, nil
+}
+
+func encodeOffset(offset int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(offset))
+	return buf
+}
+
+// WriteApplyJournal records the intent to apply the block of info to metaDB,
+// it stays until WriteBlockInfo commits the block. A non-nil undo is stored
+// in the same batch.
+func (p *DBPool) WriteApplyJournal(info *pb.BlockInfo, undo *pb.UndoLog) (err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return utils.ErrNoMetaDBRegistered
+	}
+	buf, err := proto.Marshal(info)
+	if err != nil {
+		return err
+	}
+	metaBatch := p.dbs[p.metaDBID].db.NewBatch()
+	err = metaBatch.Put([]byte(ApplyJournal), buf)
+	if err != nil {
+		return err
+	}
+	if undo != nil {
+		undoBuf, err := proto.Marshal(undo)
+		if err != nil {
+			return err
+		}
+		err = metaBatch.Put(undoLogKey(undo.Info.BlockNum), undoBuf)
+		if err != nil {
+			return err
+		}
+	}
+	return metaBatch.Write()
+}
+
+// GetApplyJournal returns the BlockInfo of an incomplete apply, or nil if
+// the last apply completed.
+func (p *DBPool) GetApplyJournal() (info *pb.BlockInfo, err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return nil, utils.ErrNoMetaDBRegistered
+	}
+	buf, err := p.dbs[p.metaDBID].db.Get([]byte(ApplyJournal))
+	if err == leveldb.ErrNotFound || (err == nil && len(buf) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info = &pb.BlockInfo{}
+	if err := proto.Unmarshal(buf, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
 // WriteBatchs writes batchs to DBs.
 func (p *DBPool) WriteBatchs(batchs []BatchWithID) (err error) {
 	for _, item := range batchs {
@@ -0,0 +1,45 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicOffset(t *testing.T) {
+	ldb, err := db.NewLDB(t.TempDir(), 16)
+	require.NoErrorf(t, err, "NewLDB error")
+	pool := db.NewDBPool()
+	pool.Register(0, "test", "leveldb", ldb, true)
+	defer pool.Close()
+
+	_, ok, err := pool.GetTopicOffset("test-eth-master-header", 0)
+	require.NoErrorf(t, err, "GetTopicOffset error")
+	require.False(t, ok)
+
+	err = pool.WriteBlockInfo(&pb.BlockInfo{BlockNum: 1, MsgOffset: 1},
+		db.TopicOffset{Topic: "test-eth-master-header", Partition: 0, Offset: 7},
+		db.TopicOffset{Topic: "test-eth-backup-header", Partition: 2, Offset: 3})
+	require.NoErrorf(t, err, "WriteBlockInfo error")
+	require.NoError(t, pool.WriteTopicOffset(db.TopicOffset{Topic: "test-bsc-master-header", Partition: 0, Offset: -1}))
+
+	for _, c := range []struct {
+		topic     string
+		partition int
+		offset    int64
+	}{
+		{"test-eth-master-header", 0, 7},
+		{"test-eth-backup-header", 2, 3},
+		{"test-bsc-master-header", 0, -1},
+	} {
+		offset, ok, err := pool.GetTopicOffset(c.topic, c.partition)
+		require.NoErrorf(t, err, "GetTopicOffset error")
+		require.True(t, ok)
+		require.Equal(t, c.offset, offset)
+	}
+	_, ok, err = pool.GetTopicOffset("test-eth-backup-header", 0)
+	require.NoErrorf(t, err, "GetTopicOffset error")
+	require.False(t, ok)
+}
@@ -0,0 +1,295 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	// SnapshotChunkSize is the max size of the key values of an exported chunk.
+	SnapshotChunkSize = 64 << 20
+)
+
+// IterableSnapshot is a Snapshot whose key values can be iterated.
+type IterableSnapshot interface {
+	Snapshot
+	NewIterator(prefix []byte, start []byte) Iterator
+}
+
+// PoolSnapshot is a consistent view of all DBs of a DBPool.
+type PoolSnapshot struct {
+	// Info is the BlockInfo of the last block applied to the snapshot, nil if none.
+	Info  *pb.BlockInfo
+	DBs   []*pb.DBInfo
+	dbs   map[int32]DB
+	snaps map[int32]IterableSnapshot
+}
+
+// Snapshot takes a snapshot of every DB. The DBs must not be written while it
+// is taken for the snapshots to be consistent with each other.
+func (p *DBPool) Snapshot() (ps *PoolSnapshot, err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return nil, utils.ErrNoMetaDBRegistered
+	}
+	ps = &PoolSnapshot{
+		dbs:   make(map[int32]DB),
+		snaps: make(map[int32]IterableSnapshot),
+	}
+	for id, db := range p.dbs {
+		snap, err := db.db.NewSnapshot()
+		if err != nil {
+			ps.Release()
+			return nil, err
+		}
+		iterable, ok := snap.(IterableSnapshot)
+		if !ok {
+			snap.Release()
+			ps.Release()
+			return nil, fmt.Errorf("snapshot of db %d can not be iterated", id)
+		}
+		ps.dbs[id] = db.db
+		ps.snaps[id] = iterable
+		ps.DBs = append(ps.DBs, &pb.DBInfo{
+			Id:     db.id,
+			DbType: db.dbType,
+			DbPath: db.path,
+			IsMeta: db.isMeta,
+		})
+	}
+	ps.Info, err = getLastBlockInfo(ps.snaps[p.metaDBID])
+	if err != nil {
+		ps.Release()
+		return nil, err
+	}
+	return ps, nil
+}
+
+// Export calls fn with the chunks of the DB id in key order, a chunk is a
+// dumped batch of about chunkSize bytes of key values which is only valid
+// until fn returns.
+func (ps *PoolSnapshot) Export(id int32, chunkSize int, fn func(chunk []byte) error) error {
+	snap, ok := ps.snaps[id]
+	if !ok {
+		return fmt.Errorf("db %d not in snapshot", id)
+	}
+	iter := snap.NewIterator(nil, nil)
+	defer iter.Release()
+	batch := ps.dbs[id].NewBatch()
+	for iter.Next() {
+		if err := batch.Put(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= chunkSize {
+			if err := fn(batch.Dump()); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if batch.ValueSize() > 0 {
+		return fn(batch.Dump())
+	}
+	return nil
+}
+
+// ExportKeys is like Export for the sorted keys of the DB id only, a key not
+// in the snapshot is exported as a deletion.
+func (ps *PoolSnapshot) ExportKeys(id int32, keys []string, chunkSize int, fn func(chunk []byte) error) error {
+	snap, ok := ps.snaps[id]
+	if !ok {
+		return fmt.Errorf("db %d not in snapshot", id)
+	}
+	batch := ps.dbs[id].NewBatch()
+	for _, key := range keys {
+		val, err := snap.Get([]byte(key))
+		switch {
+		case err == leveldb.ErrNotFound:
+			err = batch.Delete([]byte(key))
+		case err == nil:
+			err = batch.Put([]byte(key), val)
+		}
+		if err != nil {
+			return err
+		}
+		if batch.ValueSize() >= chunkSize {
+			if err := fn(batch.Dump()); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if batch.ValueSize() > 0 {
+		return fn(batch.Dump())
+	}
+	return nil
+}
+
+// Release releases the snapshots of all DBs.
+func (ps *PoolSnapshot) Release() {
+	for _, snap := range ps.snaps {
+		snap.Release()
+	}
+}
+
+// ImportChunk writes a chunk exported from a DB of the same type to db.
+func ImportChunk(db DB, chunk []byte) error {
+	batch := db.NewBatch()
+	if err := batch.Load(chunk); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+const (
+	// installSuffix names the directory a DB is imported to by Install.
+	installSuffix = ".install"
+	// installMarkerSuffix names the file listing the DB paths of an install
+	// while its DBs are moved in place.
+	installMarkerSuffix = ".installing"
+)
+
+// Install builds the DBs of layers at the paths of dbInfos, matched by id, by
+// importing the chunks of the layers of each DB in order, get returns the
+// content of a chunk. The paths must not exist or be empty. Every DB is
+// imported next to its path, then a marker listing the paths is written
+// before the DBs are moved in place, so RecoverInstall can finish an
+// interrupted install.
+func Install(dbInfos *pb.DBInfoList, layers map[int32][]*pb.SnapshotDB, cacheSize int,
+	get func(chunk *pb.SnapshotChunk) ([]byte, error)) error {
+	if err := RecoverInstall(dbInfos); err != nil {
+		return err
+	}
+	paths := make(map[int32]string)
+	var ids []int32
+	for id, dbs := range layers {
+		var path string
+		for _, dbInfo := range dbInfos.DbInfos {
+			if dbInfo.Id == id {
+				path = dbInfo.DbPath
+			}
+		}
+		if path == "" {
+			return fmt.Errorf("db %d not in db info", id)
+		}
+		for _, snapshotDB := range dbs {
+			if snapshotDB.DbInfo.DbType != "leveldb" {
+				return fmt.Errorf("db %d has unsupported type %s", id, snapshotDB.DbInfo.DbType)
+			}
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(entries) > 0 {
+			return fmt.Errorf("db %d path %s is not empty", id, path)
+		}
+		paths[id] = path
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	installed := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if err := installDB(layers[id], paths[id]+installSuffix, cacheSize, get); err != nil {
+			return fmt.Errorf("db %d: %w", id, err)
+		}
+		installed = append(installed, paths[id])
+	}
+	marker := installed[0] + installMarkerSuffix
+	if err := os.WriteFile(marker+".tmp", []byte(strings.Join(installed, "\n")), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(marker+".tmp", marker); err != nil {
+		return err
+	}
+	if err := moveInstalled(installed); err != nil {
+		return err
+	}
+	return os.Remove(marker)
+}
+
+// RecoverInstall finishes moving the DBs of an Install interrupted once they
+// were all imported, the DBs of an Install interrupted before are discarded.
+func RecoverInstall(dbInfos *pb.DBInfoList) error {
+	for _, dbInfo := range dbInfos.DbInfos {
+		marker := dbInfo.DbPath + installMarkerSuffix
+		buf, err := os.ReadFile(marker)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := moveInstalled(strings.Split(string(buf), "\n")); err != nil {
+			return err
+		}
+		if err := os.Remove(marker); err != nil {
+			return err
+		}
+	}
+	for _, dbInfo := range dbInfos.DbInfos {
+		if err := os.RemoveAll(dbInfo.DbPath + installSuffix); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(dbInfo.DbPath + installMarkerSuffix + ".tmp"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveInstalled moves the imported DBs in place at paths, the ones already
+// moved are skipped.
+func moveInstalled(paths []string) error {
+	for _, path := range paths {
+		if _, err := os.Stat(path + installSuffix); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if err := os.Rename(path+installSuffix, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func installDB(dbs []*pb.SnapshotDB, path string, cacheSize int, get func(chunk *pb.SnapshotChunk) ([]byte, error)) error {
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	ldb, err := NewLDB(path, cacheSize)
+	if err != nil {
+		return err
+	}
+	defer ldb.Close()
+	for _, snapshotDB := range dbs {
+		for _, chunk := range snapshotDB.Chunks {
+			buf, err := get(chunk)
+			if err != nil {
+				return err
+			}
+			if err := ImportChunk(ldb, buf); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package db_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverInstall(t *testing.T) {
+	dir := t.TempDir()
+	dbInfos := &pb.DBInfoList{DbInfos: []*pb.DBInfo{
+		{Id: 0, DbPath: filepath.Join(dir, "meta"), IsMeta: true},
+		{Id: 1, DbPath: filepath.Join(dir, "state")},
+	}}
+	put := func(path, value string) {
+		ldb, err := db.NewLDB(path, 16)
+		require.NoErrorf(t, err, "NewLDB error")
+		require.NoError(t, ldb.Put([]byte("key"), []byte(value)))
+		ldb.Close()
+	}
+	get := func(path string) string {
+		ldb, err := db.NewLDB(path, 16)
+		require.NoErrorf(t, err, "NewLDB error")
+		defer ldb.Close()
+		buf, err := ldb.Get([]byte("key"))
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	// an install interrupted before its marker is discarded.
+	put(dbInfos.DbInfos[0].DbPath+".install", "discarded")
+	require.NoError(t, db.RecoverInstall(dbInfos))
+	_, err := os.Stat(dbInfos.DbInfos[0].DbPath + ".install")
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(dbInfos.DbInfos[0].DbPath)
+	require.True(t, os.IsNotExist(err))
+
+	// an install interrupted after moving its first DB is finished.
+	put(dbInfos.DbInfos[0].DbPath, "meta")
+	put(dbInfos.DbInfos[1].DbPath+".install", "state")
+	paths := []string{dbInfos.DbInfos[0].DbPath, dbInfos.DbInfos[1].DbPath}
+	marker := dbInfos.DbInfos[0].DbPath + ".installing"
+	require.NoError(t, os.WriteFile(marker, []byte(strings.Join(paths, "\n")), 0644))
+	require.NoError(t, db.RecoverInstall(dbInfos))
+	require.Equal(t, "meta", get(dbInfos.DbInfos[0].DbPath))
+	require.Equal(t, "state", get(dbInfos.DbInfos[1].DbPath))
+	_, err = os.Stat(marker)
+	require.True(t, os.IsNotExist(err))
+}
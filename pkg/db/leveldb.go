@@ -97,6 +97,11 @@ func (snap *LSnapshot) Has(key []byte) (bool, error) {
 	return snap.snap.Has(key, nil)
 }
 
+func (snap *LSnapshot) NewIterator(prefix []byte, start []byte) Iterator {
+	iter := snap.snap.NewIterator(BytesPrefixRange(prefix, start), nil)
+	return &LIterator{iter: iter}
+}
+
 func (snap *LSnapshot) Release() {
 	if snap.snap != nil {
 		snap.snap.Release()
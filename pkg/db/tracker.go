@@ -0,0 +1,131 @@
+package db
+
+import (
+	"sort"
+	"sync"
+)
+
+// keySet is the set of keys written to a DB.
+type keySet struct {
+	sync.Mutex
+	keys map[string]struct{}
+}
+
+func newKeySet() *keySet {
+	return &keySet{keys: make(map[string]struct{})}
+}
+
+func (s *keySet) Put(key []byte, value []byte) error {
+	s.add(key)
+	return nil
+}
+
+func (s *keySet) Delete(key []byte) error {
+	s.add(key)
+	return nil
+}
+
+func (s *keySet) add(key []byte) {
+	s.Lock()
+	s.keys[string(key)] = struct{}{}
+	s.Unlock()
+}
+
+// swap returns the sorted keys of s and empties it.
+func (s *keySet) swap() []string {
+	s.Lock()
+	keys := make([]string, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	s.keys = make(map[string]struct{})
+	s.Unlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// trackedDB records the keys written to DB once the write succeeded, so a
+// snapshot taken after the keys are swapped out never misses a change.
+type trackedDB struct {
+	DB
+	changes *keySet
+}
+
+func (t *trackedDB) Put(key []byte, value []byte) error {
+	if err := t.DB.Put(key, value); err != nil {
+		return err
+	}
+	t.changes.add(key)
+	return nil
+}
+
+func (t *trackedDB) Delete(key []byte) error {
+	if err := t.DB.Delete(key); err != nil {
+		return err
+	}
+	t.changes.add(key)
+	return nil
+}
+
+func (t *trackedDB) NewBatch() Batch {
+	return &trackedBatch{Batch: t.DB.NewBatch(), changes: t.changes}
+}
+
+func (t *trackedDB) NewBatchWithSize(size int) Batch {
+	return &trackedBatch{Batch: t.DB.NewBatchWithSize(size), changes: t.changes}
+}
+
+type trackedBatch struct {
+	Batch
+	changes *keySet
+}
+
+func (t *trackedBatch) Write() error {
+	if err := t.Batch.Write(); err != nil {
+		return err
+	}
+	return t.Batch.Replay(t.changes)
+}
+
+// TrackChanges makes the DBs of the pool record the keys written to them for
+// SnapshotChanges. It must be called before the DBs are handed out, writes to
+// DBs or batches got before are not tracked.
+func (p *DBPool) TrackChanges() {
+	p.Lock()
+	defer p.Unlock()
+	if p.tracking {
+		return
+	}
+	p.tracking = true
+	for id, db := range p.dbs {
+		db.db = &trackedDB{DB: db.db, changes: newKeySet()}
+		p.dbs[id] = db
+	}
+}
+
+// SnapshotChanges takes a snapshot of every DB like Snapshot and returns the
+// sorted keys of every DB changed since the previous call. changed is nil if
+// the changes are not all known, before TrackChanges and on the first call
+// after it.
+func (p *DBPool) SnapshotChanges() (ps *PoolSnapshot, changed map[int32][]string, err error) {
+	p.RLock()
+	known := p.tracking && p.changesKnown
+	if p.tracking {
+		changed = make(map[int32][]string)
+		for id, db := range p.dbs {
+			changed[id] = db.db.(*trackedDB).changes.swap()
+		}
+	}
+	p.RUnlock()
+	ps, err = p.Snapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Lock()
+	p.changesKnown = p.tracking
+	p.Unlock()
+	if !known {
+		changed = nil
+	}
+	return ps, changed, nil
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/syndtr/goleveldb/leveldb"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	UndoLogPrefix = "rpl_undo/"
+)
+
+func undoLogKey(blockNum int64) []byte {
+	return []byte(fmt.Sprintf("%s%012d", UndoLogPrefix, blockNum))
+}
+
+// undoBuilder records the current value of every key a batch touches, only
+// the first write of a key matters as it holds the value before the block.
+type undoBuilder struct {
+	db    DB
+	batch Batch
+	seen  map[string]struct{}
+	err   error
+}
+
+func (u *undoBuilder) record(key []byte) {
+	if u.err != nil {
+		return
+	}
+	if _, ok := u.seen[string(key)]; ok {
+		return
+	}
+	u.seen[string(key)] = struct{}{}
+	val, err := u.db.Get(key)
+	switch {
+	case err == leveldb.ErrNotFound:
+		u.err = u.batch.Delete(key)
+	case err != nil:
+		u.err = err
+	default:
+		u.err = u.batch.Put(key, val)
+	}
+}
+
+func (u *undoBuilder) Put(key []byte, value []byte) error {
+	u.record(key)
+	return u.err
+}
+
+func (u *undoBuilder) Delete(key []byte) error {
+	u.record(key)
+	return u.err
+}
+
+// BuildUndoLog returns the UndoLog restoring the DBs to their current state
+// once items of the block of info are written. It must be called before any
+// of the items is written.
+func (p *DBPool) BuildUndoLog(info *pb.BlockInfo, items []*pb.Data) (undo *pb.UndoLog, err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return nil, utils.ErrNoMetaDBRegistered
+	}
+	prev, err := getLastBlockInfo(p.dbs[p.metaDBID].db)
+	if err != nil {
+		return nil, err
+	}
+	undo = &pb.UndoLog{
+		Info: proto.Clone(info).(*pb.BlockInfo),
+		Prev: prev,
+	}
+	builders := make(map[int32]*undoBuilder)
+	var ids []int32
+	for _, item := range items {
+		db, ok := p.dbs[item.Id]
+		if !ok {
+			return nil, leveldb.ErrNotFound
+		}
+		builder, ok := builders[item.Id]
+		if !ok {
+			builder = &undoBuilder{
+				db:    db.db,
+				batch: db.db.NewBatch(),
+				seen:  make(map[string]struct{}),
+			}
+			builders[item.Id] = builder
+			ids = append(ids, item.Id)
+		}
+		batch := db.db.NewBatch()
+		if err := batch.Load(item.Data); err != nil {
+			return nil, err
+		}
+		if err := batch.Replay(builder); err != nil {
+			return nil, err
+		}
+		if builder.err != nil {
+			return nil, builder.err
+		}
+	}
+	for _, id := range ids {
+		dump := builders[id].batch.Dump()
+		data := make([]byte, len(dump))
+		copy(data, dump)
+		undo.BatchItems = append(undo.BatchItems, &pb.Data{
+			Id:   id,
+			Data: data,
+		})
+	}
+	return undo, nil
+}
+
+// GetUndoLog returns the UndoLog of the block at blockNum, or nil if there is none.
+func (p *DBPool) GetUndoLog(blockNum int64) (undo *pb.UndoLog, err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return nil, utils.ErrNoMetaDBRegistered
+	}
+	buf, err := p.dbs[p.metaDBID].db.Get(undoLogKey(blockNum))
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	undo = &pb.UndoLog{}
+	if err := proto.Unmarshal(buf, undo); err != nil {
+		return nil, err
+	}
+	return undo, nil
+}
+
+// UndoBlock reverts the block of undo, the previous BlockInfo is restored and
+// the UndoLog removed in one batch after the DBs were reverted. Reverting is
+// idempotent so it can be retried after a failure.
+func (p *DBPool) UndoBlock(undo *pb.UndoLog) (err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return utils.ErrNoMetaDBRegistered
+	}
+	for _, item := range undo.BatchItems {
+		db, ok := p.dbs[item.Id]
+		if !ok {
+			return leveldb.ErrNotFound
+		}
+		batch := db.db.NewBatch()
+		if err := batch.Load(item.Data); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	}
+	metaBatch := p.dbs[p.metaDBID].db.NewBatch()
+	if undo.Prev != nil {
+		buf, err := proto.Marshal(undo.Prev)
+		if err != nil {
+			return err
+		}
+		err = metaBatch.Put([]byte(LastBlockInfo), buf)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = metaBatch.Delete([]byte(LastBlockInfo))
+		if err != nil {
+			return err
+		}
+	}
+	err = metaBatch.Delete(undoLogKey(undo.Info.BlockNum))
+	if err != nil {
+		return err
+	}
+	err = metaBatch.Delete([]byte(ApplyJournal))
+	if err != nil {
+		return err
+	}
+	return metaBatch.Write()
+}
+
+// PruneUndoLogs removes the UndoLogs of blocks below blockNum.
+func (p *DBPool) PruneUndoLogs(blockNum int64) (err error) {
+	p.RLock()
+	defer p.RUnlock()
+	if p.metaDBID == math.MinInt32 {
+		return utils.ErrNoMetaDBRegistered
+	}
+	db := p.dbs[p.metaDBID].db
+	iter, err := db.NewIteratorWithRange([]byte(UndoLogPrefix), undoLogKey(blockNum))
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+	batch := db.NewBatch()
+	for iter.Next() {
+		err = batch.Delete(append([]byte{}, iter.Key()...))
+		if err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if batch.ValueSize() == 0 {
+		return nil
+	}
+	return batch.Write()
+}
+
+func getLastBlockInfo(db KeyValueReader) (info *pb.BlockInfo, err error) {
+	buf, err := db.Get([]byte(LastBlockInfo))
+	if err == leveldb.ErrNotFound || (err == nil && len(buf) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info = &pb.BlockInfo{}
+	if err := proto.Unmarshal(buf, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
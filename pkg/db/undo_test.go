@@ -0,0 +1,76 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestUndoLog(t *testing.T) {
+	ldb, err := db.NewLDB(t.TempDir(), 16)
+	require.NoErrorf(t, err, "NewLDB error")
+	pool := db.NewDBPool()
+	pool.Register(0, "test", "leveldb", ldb, true)
+	defer pool.Close()
+
+	require.NoError(t, ldb.Put([]byte("a"), []byte("1")))
+	require.NoError(t, ldb.Put([]byte("c"), []byte("4")))
+	prev := &pb.BlockInfo{BlockNum: 0, BlockHash: "0"}
+	require.NoError(t, pool.WriteBlockInfo(prev))
+
+	batch := ldb.NewBatch()
+	batch.Put([]byte("a"), []byte("2"))
+	batch.Put([]byte("b"), []byte("3"))
+	batch.Delete([]byte("c"))
+	batch.Put([]byte("a"), []byte("5"))
+	items, err := pool.Marshal([]db.BatchWithID{{ID: 0, B: batch}})
+	require.NoErrorf(t, err, "Marshal error")
+
+	info := &pb.BlockInfo{BlockNum: 1, BlockHash: "1", MsgOffset: 1}
+	undo, err := pool.BuildUndoLog(info, items)
+	require.NoErrorf(t, err, "BuildUndoLog error")
+	require.NoError(t, pool.WriteApplyJournal(info, undo))
+	journal, err := pool.GetApplyJournal()
+	require.NoErrorf(t, err, "GetApplyJournal error")
+	require.Equal(t, "1", journal.BlockHash)
+	require.NoError(t, pool.WriteBatchItems(items))
+	require.NoError(t, pool.WriteBlockInfo(info))
+	journal, err = pool.GetApplyJournal()
+	require.NoErrorf(t, err, "GetApplyJournal error")
+	require.Nil(t, journal)
+
+	val, err := ldb.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("5"), val)
+
+	undo, err = pool.GetUndoLog(1)
+	require.NoErrorf(t, err, "GetUndoLog error")
+	require.Equal(t, "0", undo.Prev.BlockHash)
+	for i := 0; i < 2; i++ {
+		require.NoErrorf(t, pool.UndoBlock(undo), "UndoBlock error")
+		val, err = ldb.Get([]byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("1"), val)
+		_, err = ldb.Get([]byte("b"))
+		require.ErrorIs(t, err, leveldb.ErrNotFound)
+		val, err = ldb.Get([]byte("c"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("4"), val)
+	}
+	undo, err = pool.GetUndoLog(1)
+	require.NoErrorf(t, err, "GetUndoLog error")
+	require.Nil(t, undo)
+
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, pool.WriteApplyJournal(info, &pb.UndoLog{Info: &pb.BlockInfo{BlockNum: i}}))
+	}
+	require.NoError(t, pool.PruneUndoLogs(3))
+	for i := int64(1); i <= 3; i++ {
+		undo, err = pool.GetUndoLog(i)
+		require.NoErrorf(t, err, "GetUndoLog error")
+		require.Equal(t, i == 3, undo != nil)
+	}
+}
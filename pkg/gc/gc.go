@@ -0,0 +1,254 @@
+package gc
+
+import (
+	"context"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// listBatch is the number of headers listed and removed at once.
+	listBatch = 256
+)
+
+// Config selects the block objects removed by a Collector.
+type Config struct {
+	Env     string
+	ChainId string
+	Roles   []string
+	// KeepBlocks is the number of blocks kept below the highest reader height
+	// of a role.
+	KeepBlocks int64
+	// ReorgWindow is the number of blocks kept below the lowest reader height
+	// of a role for the readers to roll back, at least their ReorgDeep and
+	// UndoWindow. It must be positive.
+	ReorgWindow int64
+	// KeepWindow keeps the objects modified within it, 0 disables it.
+	KeepWindow time.Duration
+	// Checkpoints are the names of the reader checkpoints whose latest one is
+	// kept restorable.
+	Checkpoints []string
+	// DryRun only reports what would be removed.
+	DryRun bool
+}
+
+// Result is the outcome of a collection of one role.
+type Result struct {
+	Role string
+	// Cutoff is the block number below which objects are removed, -1 if
+	// nothing can be removed.
+	Cutoff       int64
+	RemovedFiles int64
+	RemovedBytes int64
+}
+
+// Collector removes the header and data objects of old blocks of each role.
+// Objects of blocks within ReorgWindow below the applied height of the oldest
+// live reader of the role, or at or above the latest snapshot or the latest
+// reader checkpoints of the role are never removed.
+type Collector struct {
+	config  *Config
+	s3      *s3.Client
+	ndrc    pb.SubscribeServiceClient
+	metrics *metrics.GCMetrics
+}
+
+func NewCollector(config *Config, s3 *s3.Client, ndrc pb.SubscribeServiceClient) (*Collector, error) {
+	if config.ReorgWindow <= 0 || config.KeepBlocks < 0 {
+		return nil, utils.ErrInvalidConfig
+	}
+	return &Collector{
+		config:  config,
+		s3:      s3,
+		ndrc:    ndrc,
+		metrics: metrics.NewGCMetrics(),
+	}, nil
+}
+
+// Run collects every interval until ctx is done.
+func (c *Collector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, err := c.RunOnce(ctx)
+		if err != nil {
+			utils.Logger().Error("gc error", zap.Error(err))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce collects the objects of every role once.
+func (c *Collector) RunOnce(ctx context.Context) ([]*Result, error) {
+	rsp, err := c.ndrc.ListReader(ctx, &pb.ListReaderRequest{})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*Result, 0, len(c.config.Roles))
+	for _, role := range c.config.Roles {
+		result, err := c.collect(ctx, role, c.readerCutoff(rsp.Readers, role))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// readerCutoff returns the lowest height of the live readers of role, capped
+// by KeepBlocks below the highest one, less ReorgWindow. Readers not reporting
+// their role count for every role. It is -1 without live readers, their
+// heights are unknown then.
+func (c *Collector) readerCutoff(readers []*pb.NodeId, role string) int64 {
+	found := false
+	var lowest, highest int64
+	for _, reader := range readers {
+		if reader.Env != c.config.Env || reader.ChainId != c.config.ChainId {
+			continue
+		}
+		if blockRole := reader.GetBlockUpdateInfo().GetBlockRole(); blockRole != "" && blockRole != role {
+			continue
+		}
+		height := reader.GetBlockUpdateInfo().GetBlockNum()
+		if !found || height < lowest {
+			lowest = height
+		}
+		if !found || height > highest {
+			highest = height
+		}
+		found = true
+	}
+	if !found {
+		utils.Logger().Warn("gc found no live reader", zap.String("env", c.config.Env), zap.String("chainId", c.config.ChainId), zap.String("role", role))
+		return -1
+	}
+	if highest-c.config.KeepBlocks < lowest {
+		lowest = highest - c.config.KeepBlocks
+	}
+	if lowest < c.config.ReorgWindow {
+		return 0
+	}
+	return lowest - c.config.ReorgWindow
+}
+
+// snapshotHeight returns the height of the latest snapshot of role, -1 if
+// there is none.
+func (c *Collector) snapshotHeight(ctx context.Context, role string) (int64, error) {
+	return c.manifestHeight(ctx, utils.SnapshotLatestKey(c.config.Env, c.config.ChainId, role))
+}
+
+// checkpointHeight returns the lowest height of the latest checkpoints of
+// role, -1 if there is none.
+func (c *Collector) checkpointHeight(ctx context.Context, role string) (int64, error) {
+	lowest := int64(-1)
+	for _, name := range c.config.Checkpoints {
+		prefix := utils.CheckpointPrefix(c.config.Env, c.config.ChainId, role, name)
+		height, err := c.manifestHeight(ctx, utils.CheckpointLatestKey(prefix))
+		if err != nil {
+			return -1, err
+		}
+		if height >= 0 && (lowest < 0 || height < lowest) {
+			lowest = height
+		}
+	}
+	return lowest, nil
+}
+
+// manifestHeight returns the height of the manifest whose key is held by
+// latestKey, -1 if there is none.
+func (c *Collector) manifestHeight(ctx context.Context, latestKey string) (int64, error) {
+	manifestKey, err := c.s3.GetFile(ctx, latestKey)
+	if err != nil {
+		return -1, err
+	}
+	if len(manifestKey) == 0 {
+		return -1, nil
+	}
+	buf, err := c.s3.GetFile(ctx, string(manifestKey))
+	if err != nil {
+		return -1, err
+	}
+	manifest := &pb.Snapshot{}
+	if err := proto.Unmarshal(buf, manifest); err != nil {
+		return -1, err
+	}
+	return manifest.Info.GetBlockNum(), nil
+}
+
+func (c *Collector) collect(ctx context.Context, role string, cutoff int64) (*Result, error) {
+	prefix := utils.TopicPrefix(c.config.Env, c.config.ChainId, role)
+	result := &Result{Role: role, Cutoff: cutoff}
+	snapshot, err := c.snapshotHeight(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot >= 0 && snapshot < result.Cutoff {
+		result.Cutoff = snapshot
+	}
+	checkpoint, err := c.checkpointHeight(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint >= 0 && checkpoint < result.Cutoff {
+		result.Cutoff = checkpoint
+	}
+	c.metrics.SetCutoff(prefix, result.Cutoff)
+	if result.Cutoff <= 0 {
+		return result, nil
+	}
+	var modifiedBefore time.Time
+	if c.config.KeepWindow > 0 {
+		modifiedBefore = time.Now().Add(-c.config.KeepWindow)
+	}
+
+	var batch []*pb.BlockInfo
+	kept := false
+	remove := func() error {
+		rsp, err := c.s3.RemoveFilesBefore(ctx, batch, modifiedBefore, c.config.DryRun)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		result.RemovedFiles += rsp.RemovedFiles
+		result.RemovedBytes += rsp.RemovedBytes
+		c.metrics.IncreaseRemoved(prefix, c.config.DryRun, rsp.RemovedFiles, rsp.RemovedBytes)
+		// objects of higher blocks are newer than the ones kept.
+		kept = len(rsp.Kept) > 0
+		return nil
+	}
+	var removeErr error
+	err = c.s3.ListHeaders(ctx, c.config.ChainId, c.config.Env, role, 0, result.Cutoff, -1, func(info *pb.BlockInfo) bool {
+		// the data goes first, a header is only removed with its block.
+		data := proto.Clone(info).(*pb.BlockInfo)
+		data.BlockType = pb.BlockInfo_DATA
+		batch = append(batch, data, info)
+		if len(batch) < 2*listBatch {
+			return true
+		}
+		removeErr = remove()
+		return removeErr == nil && !kept
+	})
+	if err == nil {
+		err = removeErr
+	}
+	if err == nil && len(batch) > 0 && !kept {
+		err = remove()
+	}
+	if err != nil {
+		return nil, err
+	}
+	utils.Logger().Info("gc", zap.String("prefix", prefix), zap.Int64("cutoff", result.Cutoff),
+		zap.Int64("removedFiles", result.RemovedFiles), zap.Int64("removedBytes", result.RemovedBytes),
+		zap.Bool("dryRun", c.config.DryRun))
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package gc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type readerLister struct {
+	pb.SubscribeServiceClient
+	readers []*pb.NodeId
+}
+
+func (l *readerLister) ListReader(ctx context.Context, in *pb.ListReaderRequest, opts ...grpc.CallOption) (*pb.ListReaderResponse, error) {
+	return &pb.ListReaderResponse{Readers: l.readers}, nil
+}
+
+func reader(role string, height int64) *pb.NodeId {
+	return &pb.NodeId{
+		Env:             "test",
+		ChainId:         "256",
+		Role:            pb.NodeRole_READER,
+		BlockUpdateInfo: &pb.BlockUpdateInfo{BlockNum: height, BlockRole: role},
+	}
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	store := s3.NewMemStore()
+	go s3.ListenAndServe("0.0.0.0:8771", 32, store)
+	client, err := s3.NewClient("0.0.0.0:8771")
+	require.NoErrorf(t, err, "NewClient error")
+
+	infos := make([]*pb.BlockInfo, 10)
+	for i := range infos {
+		infos[i] = &pb.BlockInfo{
+			Env:       "test",
+			ChainId:   "256",
+			Role:      "master",
+			BlockNum:  int64(i),
+			BlockHash: strconv.Itoa(i),
+			MsgOffset: int64(i),
+		}
+		require.NoError(t, store.Put(ctx, utils.HeaderPrefix(infos[i]), []byte("header")))
+		require.NoError(t, store.Put(ctx, utils.BlockPrefix(infos[i]), []byte("block")))
+	}
+	manifest := &pb.Snapshot{Info: infos[4]}
+	buf, err := proto.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, "manifest", buf))
+	require.NoError(t, store.Put(ctx, utils.SnapshotLatestKey("test", "256", "master"), []byte("manifest")))
+
+	lister := &readerLister{}
+	config := &Config{Env: "test", ChainId: "256", Roles: []string{"master"}, KeepBlocks: 3, DryRun: true}
+	_, err = NewCollector(config, client, lister)
+	require.ErrorIs(t, err, utils.ErrInvalidConfig)
+	config.ReorgWindow = 1
+	collector, err := NewCollector(config, client, lister)
+	require.NoError(t, err)
+	var results []*Result
+	require.Eventually(t, func() bool {
+		results, err = collector.RunOnce(ctx)
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	// without live readers nothing is removed.
+	require.Equal(t, int64(-1), results[0].Cutoff)
+
+	// the reorg window below the lowest reader of the role is kept.
+	lister.readers = []*pb.NodeId{reader("master", 9), reader("master", 7), reader("backup", 1)}
+	config.KeepBlocks = 0
+	config.ReorgWindow = 4
+	results, err = collector.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), results[0].Cutoff)
+
+	// the snapshot at 4 is below the readers and KeepBlocks, a reader not
+	// reporting its role counts for every role.
+	lister.readers = []*pb.NodeId{reader("master", 9), reader("", 7), reader("backup", 1)}
+	config.KeepBlocks = 3
+	config.ReorgWindow = 1
+	results, err = collector.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &Result{Role: "master", Cutoff: 4, RemovedFiles: 8, RemovedBytes: 4 * int64(len("header")+len("block"))}, results[0])
+	stat, err := store.Stat(ctx, utils.HeaderPrefix(infos[0]))
+	require.NoError(t, err)
+	require.NotNil(t, stat)
+
+	// the latest reader checkpoint at 2 is below the snapshot.
+	checkpoint := &pb.Snapshot{Key: "checkpoint", Info: infos[2]}
+	buf, err = proto.Marshal(checkpoint)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, checkpoint.Key, buf))
+	prefix := utils.CheckpointPrefix("test", "256", "master", "default")
+	require.NoError(t, store.Put(ctx, utils.CheckpointLatestKey(prefix), []byte(checkpoint.Key)))
+	config.Checkpoints = []string{"default", "missing"}
+	results, err = collector.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), results[0].Cutoff)
+	config.Checkpoints = nil
+
+	// objects within the window are kept.
+	config.DryRun = false
+	config.KeepWindow = time.Hour
+	results, err = collector.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), results[0].RemovedFiles)
+
+	config.KeepWindow = 0
+	results, err = collector.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), results[0].RemovedFiles)
+	for _, info := range infos {
+		header, err := store.Stat(ctx, utils.HeaderPrefix(info))
+		require.NoError(t, err)
+		block, err := store.Stat(ctx, utils.BlockPrefix(info))
+		require.NoError(t, err)
+		require.Equal(t, info.BlockNum >= 4, header != nil, info.BlockNum)
+		require.Equal(t, info.BlockNum >= 4, block != nil, info.BlockNum)
+	}
+}
@@ -0,0 +1,162 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	BusKafka  = "kafka"
+	BusRedis  = "redis"
+	BusMemory = "memory"
+)
+
+// HeaderBus broadcasts the BlockInfos of a chain from the writer to the
+// readers. Messages of a topic partition have consecutive offsets starting at
+// 0, the offset of a block is its MsgOffset.
+type HeaderBus interface {
+	Topic() string
+	Partition() int
+	ResetTopic(topic string)
+
+	// RemoteOffset returns the first and the last offset of the topic partition.
+	RemoteOffset() (firstOffset int64, lastOffset int64, err error)
+	LastWriterOffset() int64
+	Broadcast(ctx context.Context, info *pb.BlockInfo) error
+
+	LastReaderOffset() int64
+	IncrementLastReaderOffset()
+	ResetLastReaderOffset(offset int64)
+	// Fetch returns the BlockInfos after the last reader offset.
+	Fetch(ctx context.Context) (infos []*pb.BlockInfo, err error)
+	// FetchStart returns the BlockInfos starting at offset start.
+	FetchStart(ctx context.Context, start int64) (infos []*pb.BlockInfo, err error)
+
+	// CommitOffset commits offset as the last applied offset of the consumer group groupID.
+	CommitOffset(ctx context.Context, groupID string, offset int64) error
+	// CommittedOffset returns the last applied offset committed by the consumer
+	// group groupID, ok is false if the group has not committed one.
+	CommittedOffset(ctx context.Context, groupID string) (offset int64, ok bool, err error)
+
+	// Sync loads the writer offset from the remote offsets and checks that the
+	// messages after the reader offset are still kept.
+	Sync() error
+}
+
+// NewHeaderBus creates the HeaderBus selected by config.HeaderBus for the
+// configured partition of topic and syncs it, kafka is used by default.
+func NewHeaderBus(config *utils.Config, topic string, readerLastOffset int64) (HeaderBus, error) {
+	bus, err := DialHeaderBus(config, topic, readerLastOffset)
+	if err != nil {
+		return nil, err
+	}
+	if err := bus.Sync(); err != nil {
+		return nil, err
+	}
+	return bus, nil
+}
+
+// DialHeaderBus creates the HeaderBus selected by config.HeaderBus without
+// syncing it, so it can be used once the bus is reachable.
+func DialHeaderBus(config *utils.Config, topic string, readerLastOffset int64) (HeaderBus, error) {
+	switch config.HeaderBus {
+	case "", BusKafka:
+		return newKafkaClient(topic, config.KafkaPartition, readerLastOffset, config.KafkaAddr), nil
+	case BusRedis:
+		return newRedisBus(topic, config.KafkaPartition, readerLastOffset, config.RedisAddr), nil
+	case BusMemory:
+		return newMemoryBus(topic, config.KafkaPartition, readerLastOffset), nil
+	default:
+		return nil, fmt.Errorf("unknown header bus: %s", config.HeaderBus)
+	}
+}
+
+// offsets keeps the writer and reader offsets of the topic partition of a
+// HeaderBus.
+type offsets struct {
+	writerLastOffset int64
+	readerLastOffset int64
+	topic            string
+	partition        int
+	synced           bool
+	kafkaMetrics     *metrics.KafkaMetrics
+}
+
+func newOffsets(topic string, partition int, readerLastOffset int64) offsets {
+	o := offsets{
+		readerLastOffset: readerLastOffset,
+		topic:            topic,
+		partition:        partition,
+		kafkaMetrics:     metrics.NewKafkaMetrics(),
+	}
+	o.kafkaMetrics.SwitchTopic("init", topic)
+	return o
+}
+
+// sync implements Sync with the remote offsets of bus.
+func (o *offsets) sync(bus HeaderBus) error {
+	writeFirstOffset, writeLastOffset, err := bus.RemoteOffset()
+	if err != nil {
+		return err
+	}
+	if !o.synced {
+		if o.readerLastOffset > 0 {
+			o.kafkaMetrics.IncreaseReaderOffset(o.topic, o.readerLastOffset)
+		}
+		if writeLastOffset > 0 {
+			o.kafkaMetrics.IncreaseWriterOffset(o.topic, writeLastOffset)
+		}
+	}
+	utils.Logger().Info("remote offset", zap.Any("readerLastOffset", o.readerLastOffset), zap.Any("writeFirstOffset", writeFirstOffset), zap.Any("writeLastOffset ", writeLastOffset))
+
+	if o.readerLastOffset < writeFirstOffset-1 {
+		utils.Logger().Error("remote first offset less than reader last offset", zap.Any("readerLastOffset", o.readerLastOffset), zap.Any("writeFirstOffset", writeFirstOffset))
+		return errors.New("remote first offset less than reader last offset")
+	}
+	o.writerLastOffset = writeLastOffset
+	o.synced = true
+	return nil
+}
+
+func (o *offsets) ResetTopic(topic string) {
+	o.kafkaMetrics.SwitchTopic(o.topic, topic)
+	o.topic = topic
+}
+
+func (o *offsets) Topic() string {
+	return o.topic
+}
+
+func (o *offsets) Partition() int {
+	return o.partition
+}
+
+func (o *offsets) LastWriterOffset() int64 {
+	return o.writerLastOffset
+}
+
+func (o *offsets) incrementLastWriterOffset() {
+	o.writerLastOffset++
+	if o.writerLastOffset > 0 {
+		o.kafkaMetrics.IncreaseWriterOffset(o.topic, 1)
+	}
+}
+
+func (o *offsets) IncrementLastReaderOffset() {
+	o.readerLastOffset++
+	o.kafkaMetrics.IncreaseReaderOffset(o.topic, 1)
+}
+
+func (o *offsets) LastReaderOffset() int64 {
+	return o.readerLastOffset
+}
+
+func (o *offsets) ResetLastReaderOffset(offset int64) {
+	o.readerLastOffset = offset
+}
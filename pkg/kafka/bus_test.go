@@ -0,0 +1,114 @@
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMemoryBus(t *testing.T) {
+	writer, err := NewMemoryBus("memory-test", 0, 1<<63-1)
+	require.NoError(t, err)
+	reader, err := NewMemoryBus("memory-test", 0, -1)
+	require.NoError(t, err)
+
+	infos, err := reader.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, infos)
+
+	for i := int64(0); i < 3; i++ {
+		require.NoError(t, writer.Broadcast(context.Background(), &pb.BlockInfo{BlockNum: i, MsgOffset: i}))
+	}
+	require.Equal(t, int64(2), writer.LastWriterOffset())
+	first, last, err := reader.RemoteOffset()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), first)
+	require.Equal(t, int64(2), last)
+
+	reader.IncrementLastReaderOffset()
+	infos, err = reader.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(infos))
+	require.Equal(t, int64(1), infos[0].BlockNum)
+
+	_, ok, err := reader.CommittedOffset(context.Background(), "group")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, reader.CommitOffset(context.Background(), "group", 2))
+	offset, ok, err := reader.CommittedOffset(context.Background(), "group")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(2), offset)
+}
+
+func TestRedisBus(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	info, err := proto.Marshal(&pb.BlockInfo{BlockNum: 7})
+	require.NoError(t, err)
+	entry := func(id string) string {
+		return "*2\r\n$" + strconv.Itoa(len(id)) + "\r\n" + id + "\r\n*2\r\n$4\r\ninfo\r\n$" +
+			strconv.Itoa(len(info)) + "\r\n" + string(info) + "\r\n"
+	}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rd := bufio.NewReader(conn)
+		key := "nodex/redis-test/0"
+		replies := []string{
+			// HELLO, the bus speaks RESP2 to servers without it.
+			"-ERR unknown command 'HELLO'\r\n",
+			"*1\r\n" + entry("0-8"),
+			"*1\r\n" + entry("0-10"),
+			"*1\r\n*2\r\n$" + strconv.Itoa(len(key)) + "\r\n" + key + "\r\n*1\r\n" + entry("0-8"),
+			"-ERR The ID specified in XADD is equal or smaller than the target stream top item\r\n",
+			"$-1\r\n",
+		}
+		for _, reply := range replies {
+			line, err := rd.ReadString('\n')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(line[1 : len(line)-2])
+			// skip the bulk strings of the command.
+			for i := 0; i < 2*n; i++ {
+				if _, err := rd.ReadString('\n'); err != nil {
+					return
+				}
+			}
+			conn.Write([]byte(reply))
+		}
+	}()
+
+	bus := newRedisBus("redis-test", 0, -1, ln.Addr().String())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	first, last, err := bus.RemoteOffset()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), first)
+	require.Equal(t, int64(9), last)
+
+	infos, err := bus.FetchStart(ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(infos))
+	require.Equal(t, int64(7), infos[0].BlockNum)
+
+	writerOffset := bus.LastWriterOffset()
+	require.Error(t, bus.Broadcast(ctx, &pb.BlockInfo{BlockNum: 8}))
+	require.Equal(t, writerOffset, bus.LastWriterOffset())
+
+	_, ok, err := bus.CommittedOffset(ctx, "group")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
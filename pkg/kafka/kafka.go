@@ -3,11 +3,11 @@ package kafka
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"sync"
 	"time"
 
-	"github.com/DeBankDeFi/nodex/pkg/metrics"
 	"github.com/DeBankDeFi/nodex/pkg/pb"
 	"github.com/DeBankDeFi/nodex/pkg/utils"
 	"github.com/segmentio/kafka-go"
@@ -20,56 +20,40 @@ const (
 	KafkaMaxWait  = 500 * time.Millisecond
 )
 
+// KafkaClient is the HeaderBus backed by a kafka topic partition.
 type KafkaClient struct {
-	client           *kafka.Client
-	writerLastOffset int64
-	readerLastOffset int64
-	topic            string
-	kafkaMetrics     *metrics.KafkaMetrics
+	offsets
+	client *kafka.Client
 	sync.RWMutex
 }
 
 func NewKafkaClient(topic string, readerLastOffset int64, addrs ...string) (*KafkaClient, error) {
-	kafka := &kafka.Client{
-		Addr:    kafka.TCP(addrs...),
-		Timeout: time.Second * 10,
-	}
-	client := &KafkaClient{
-		client:           kafka,
-		readerLastOffset: readerLastOffset,
-		topic:            topic,
-		kafkaMetrics:     metrics.NewKafkaMetrics(),
-	}
-
-	client.kafkaMetrics.SwitchTopic("init", topic)
+	return NewKafkaClientWithPartition(topic, 0, readerLastOffset, addrs...)
+}
 
-	writeFirstOffset, writeLastOffset, err := client.RemoteOffset()
-	if err != nil {
+// NewKafkaClientWithPartition creates a KafkaClient which reads and writes
+// the given partition of topic.
+func NewKafkaClientWithPartition(topic string, partition int, readerLastOffset int64, addrs ...string) (*KafkaClient, error) {
+	client := newKafkaClient(topic, partition, readerLastOffset, addrs...)
+	if err := client.Sync(); err != nil {
 		return nil, err
 	}
-	if readerLastOffset > 0 {
-		client.kafkaMetrics.IncreaseReaderOffset(topic, readerLastOffset)
-	}
-	if writeLastOffset > 0 {
-		client.kafkaMetrics.IncreaseWriterOffset(topic, writeLastOffset)
-	}
-	utils.Logger().Info("remote offset", zap.Any("readerLastOffset", readerLastOffset), zap.Any("writeFirstOffset", writeFirstOffset), zap.Any("writeLastOffset ", writeLastOffset))
-
-	if readerLastOffset < writeFirstOffset-1 {
-		utils.Logger().Error("remote first offset less than reader last offset", zap.Any("readerLastOffset", readerLastOffset), zap.Any("writeFirstOffset", writeFirstOffset))
-		return nil, errors.New("remote first offset less than reader last offset")
-	}
-	client.writerLastOffset = writeLastOffset
 	return client, nil
 }
 
-func (k *KafkaClient) ResetTopic(topic string) {
-	k.kafkaMetrics.SwitchTopic(k.topic, topic)
-	k.topic = topic
+func newKafkaClient(topic string, partition int, readerLastOffset int64, addrs ...string) *KafkaClient {
+	kafka := &kafka.Client{
+		Addr:    kafka.TCP(addrs...),
+		Timeout: time.Second * 10,
+	}
+	return &KafkaClient{
+		offsets: newOffsets(topic, partition, readerLastOffset),
+		client:  kafka,
+	}
 }
 
-func (k *KafkaClient) LastWriterOffset() int64 {
-	return k.writerLastOffset
+func (k *KafkaClient) Sync() error {
+	return k.sync(k)
 }
 
 func (k *KafkaClient) RemoteOffset() (firstOffset int64, lastOffset int64, err error) {
@@ -78,11 +62,11 @@ func (k *KafkaClient) RemoteOffset() (firstOffset int64, lastOffset int64, err e
 		Topics: map[string][]kafka.OffsetRequest{
 			k.topic: {
 				{
-					Partition: 0,
+					Partition: k.partition,
 					Timestamp: kafka.FirstOffset,
 				},
 				{
-					Partition: 0,
+					Partition: k.partition,
 					Timestamp: kafka.LastOffset,
 				},
 			},
@@ -92,20 +76,73 @@ func (k *KafkaClient) RemoteOffset() (firstOffset int64, lastOffset int64, err e
 	if err != nil {
 		return -1, -1, err
 	}
-	return rsp.Topics[k.topic][0].FirstOffset, rsp.Topics[k.topic][0].LastOffset - 1, nil
-}
-
-func (k *KafkaClient) IncrementLastReaderOffset() {
-	k.readerLastOffset++
-	k.kafkaMetrics.IncreaseReaderOffset(k.topic, 1)
+	for _, partition := range rsp.Topics[k.topic] {
+		if partition.Partition == k.partition {
+			if partition.Error != nil {
+				return -1, -1, partition.Error
+			}
+			return partition.FirstOffset, partition.LastOffset - 1, nil
+		}
+	}
+	return -1, -1, fmt.Errorf("partition %d of topic %s not found", k.partition, k.topic)
 }
 
-func (k *KafkaClient) LastReaderOffset() int64 {
-	return k.readerLastOffset
+// CommitOffset commits offset as the last applied offset of the consumer
+// group groupID, kafka stores the next offset to read so offset+1 is sent.
+func (k *KafkaClient) CommitOffset(ctx context.Context, groupID string, offset int64) error {
+	rsp, err := k.client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		Addr:         k.client.Addr,
+		GroupID:      groupID,
+		GenerationID: -1,
+		Topics: map[string][]kafka.OffsetCommit{
+			k.topic: {
+				{
+					Partition: k.partition,
+					Offset:    offset + 1,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, partition := range rsp.Topics[k.topic] {
+		if partition.Partition == k.partition && partition.Error != nil {
+			return partition.Error
+		}
+	}
+	return nil
 }
 
-func (k *KafkaClient) ResetLastReaderOffset(offset int64) {
-	k.readerLastOffset = offset
+// CommittedOffset returns the last applied offset committed by the consumer
+// group groupID, ok is false if the group has not committed one.
+func (k *KafkaClient) CommittedOffset(ctx context.Context, groupID string) (offset int64, ok bool, err error) {
+	rsp, err := k.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		Addr:    k.client.Addr,
+		GroupID: groupID,
+		Topics: map[string][]int{
+			k.topic: {k.partition},
+		},
+	})
+	if err != nil {
+		return -1, false, err
+	}
+	if rsp.Error != nil {
+		return -1, false, rsp.Error
+	}
+	for _, partition := range rsp.Topics[k.topic] {
+		if partition.Partition != k.partition {
+			continue
+		}
+		if partition.Error != nil {
+			return -1, false, partition.Error
+		}
+		if partition.CommittedOffset < 0 {
+			return -1, false, nil
+		}
+		return partition.CommittedOffset - 1, true, nil
+	}
+	return -1, false, nil
 }
 
 func (k *KafkaClient) Broadcast(ctx context.Context, info *pb.BlockInfo) error {
@@ -122,17 +159,14 @@ func (k *KafkaClient) Broadcast(ctx context.Context, info *pb.BlockInfo) error {
 func (k *KafkaClient) broadcast(ctx context.Context, record kafka.Record) error {
 	rsp, err := k.client.Produce(ctx, &kafka.ProduceRequest{
 		Topic:        k.topic,
-		Partition:    0,
+		Partition:    k.partition,
 		RequiredAcks: kafka.RequireAll,
 		Records:      kafka.NewRecordReader(record),
 	})
 	if err != nil {
 		return err
 	}
-	k.writerLastOffset++
-	if k.writerLastOffset > 0 {
-		k.kafkaMetrics.IncreaseWriterOffset(k.topic, 1)
-	}
+	k.incrementLastWriterOffset()
 	utils.Logger().Info("broadcast", zap.Any("BaseOffset", rsp.BaseOffset), zap.Any("writerLastOffset", k.writerLastOffset))
 	return nil
 }
@@ -167,7 +201,7 @@ func (k *KafkaClient) fetch(ctx context.Context, start int64) (records []*kafka.
 	startTime := time.Now()
 	rsp, err := k.client.Fetch(ctx, &kafka.FetchRequest{
 		Topic:     k.topic,
-		Partition: 0,
+		Partition: k.partition,
 		MinBytes:  1,
 		MaxBytes:  KafkaMaxBytes,
 		MaxWait:   KafkaMaxWait,
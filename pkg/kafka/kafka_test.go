@@ -42,4 +42,10 @@ func TestKafka(t *testing.T) {
 	require.Equal(t, true, proto.Equal(info1, infos[1]))
 	require.Equal(t, true, proto.Equal(info2, infos[2]))
 	require.Equal(t, int64(2), client.LastReaderOffset())
+
+	require.NoError(t, client.CommitOffset(context.Background(), "test-group", client.LastReaderOffset()))
+	offset, ok, err := client.CommittedOffset(context.Background(), "test-group")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, client.LastReaderOffset(), offset)
 }
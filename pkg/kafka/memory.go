@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryLog is an in-process topic partition, the offset of a message is its
+// index in records.
+type memoryLog struct {
+	sync.Mutex
+	records [][]byte
+	groups  map[string]int64
+	// notify is closed and replaced when a record is appended.
+	notify chan struct{}
+}
+
+var (
+	memoryLogs   = make(map[string]*memoryLog)
+	memoryLogsMu sync.Mutex
+)
+
+func getMemoryLog(topic string, partition int) *memoryLog {
+	memoryLogsMu.Lock()
+	defer memoryLogsMu.Unlock()
+	key := fmt.Sprintf("%s/%d", topic, partition)
+	log, ok := memoryLogs[key]
+	if !ok {
+		log = &memoryLog{
+			groups: make(map[string]int64),
+			notify: make(chan struct{}),
+		}
+		memoryLogs[key] = log
+	}
+	return log
+}
+
+// MemoryBus is the HeaderBus kept in process memory, the writers and readers
+// of a process sharing a topic see the same messages. It is meant for tests
+// and single process deployments, the messages are lost on exit.
+type MemoryBus struct {
+	offsets
+}
+
+func NewMemoryBus(topic string, partition int, readerLastOffset int64) (*MemoryBus, error) {
+	bus := newMemoryBus(topic, partition, readerLastOffset)
+	if err := bus.Sync(); err != nil {
+		return nil, err
+	}
+	return bus, nil
+}
+
+func newMemoryBus(topic string, partition int, readerLastOffset int64) *MemoryBus {
+	return &MemoryBus{
+		offsets: newOffsets(topic, partition, readerLastOffset),
+	}
+}
+
+func (m *MemoryBus) Sync() error {
+	return m.sync(m)
+}
+
+func (m *MemoryBus) log() *memoryLog {
+	return getMemoryLog(m.topic, m.partition)
+}
+
+func (m *MemoryBus) RemoteOffset() (firstOffset int64, lastOffset int64, err error) {
+	log := m.log()
+	log.Lock()
+	defer log.Unlock()
+	return 0, int64(len(log.records)) - 1, nil
+}
+
+func (m *MemoryBus) Broadcast(ctx context.Context, info *pb.BlockInfo) error {
+	value, err := proto.Marshal(info)
+	if err != nil {
+		return err
+	}
+	log := m.log()
+	log.Lock()
+	log.records = append(log.records, value)
+	close(log.notify)
+	log.notify = make(chan struct{})
+	log.Unlock()
+	m.incrementLastWriterOffset()
+	return nil
+}
+
+func (m *MemoryBus) Fetch(ctx context.Context) (infos []*pb.BlockInfo, err error) {
+	return m.FetchStart(ctx, m.readerLastOffset+1)
+}
+
+// FetchStart waits up to KafkaMaxWait for a message at start and returns at
+// most KafkaMaxBytes of messages.
+func (m *MemoryBus) FetchStart(ctx context.Context, start int64) (infos []*pb.BlockInfo, err error) {
+	if start < 0 {
+		start = 0
+	}
+	log := m.log()
+	log.Lock()
+	if start >= int64(len(log.records)) {
+		notify := log.notify
+		log.Unlock()
+		timer := time.NewTimer(KafkaMaxWait)
+		defer timer.Stop()
+		select {
+		case <-notify:
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		log.Lock()
+	}
+	var records [][]byte
+	size := 0
+	for offset := start; offset < int64(len(log.records)) && size < KafkaMaxBytes; offset++ {
+		records = append(records, log.records[offset])
+		size += len(log.records[offset])
+	}
+	log.Unlock()
+	for _, record := range records {
+		info := &pb.BlockInfo{}
+		if err := proto.Unmarshal(record, info); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (m *MemoryBus) CommitOffset(ctx context.Context, groupID string, offset int64) error {
+	log := m.log()
+	log.Lock()
+	defer log.Unlock()
+	log.groups[groupID] = offset
+	return nil
+}
+
+func (m *MemoryBus) CommittedOffset(ctx context.Context, groupID string) (offset int64, ok bool, err error) {
+	log := m.log()
+	log.Lock()
+	defer log.Unlock()
+	offset, ok = log.groups[groupID]
+	if !ok {
+		return -1, false, nil
+	}
+	return offset, true, nil
+}
@@ -0,0 +1,174 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	redisTimeout    = 10 * time.Second
+	redisFetchCount = 1024
+	redisInfoField  = "info"
+)
+
+// RedisBus is the HeaderBus backed by a redis stream per topic partition.
+// The message at offset n has the explicit stream id 0-(n+1), so offsets stay
+// consecutive and a stale writer can not append behind a newer one.
+type RedisBus struct {
+	offsets
+	client *redis.Client
+}
+
+func NewRedisBus(topic string, partition int, readerLastOffset int64, addr string) (*RedisBus, error) {
+	bus := newRedisBus(topic, partition, readerLastOffset, addr)
+	if err := bus.Sync(); err != nil {
+		return nil, err
+	}
+	return bus, nil
+}
+
+func newRedisBus(topic string, partition int, readerLastOffset int64, addr string) *RedisBus {
+	return &RedisBus{
+		offsets: newOffsets(topic, partition, readerLastOffset),
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			DialTimeout:  redisTimeout,
+			ReadTimeout:  redisTimeout,
+			WriteTimeout: redisTimeout,
+		}),
+	}
+}
+
+func (r *RedisBus) Sync() error {
+	return r.sync(r)
+}
+
+func (r *RedisBus) streamKey() string {
+	return fmt.Sprintf("nodex/%s/%d", r.topic, r.partition)
+}
+
+func (r *RedisBus) groupsKey() string {
+	return r.streamKey() + "/groups"
+}
+
+func redisStreamID(offset int64) string {
+	return fmt.Sprintf("0-%d", offset+1)
+}
+
+func redisStreamOffset(id string) (int64, error) {
+	_, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return -1, fmt.Errorf("invalid stream id: %s", id)
+	}
+	n, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil {
+		return -1, err
+	}
+	return n - 1, nil
+}
+
+func (r *RedisBus) RemoteOffset() (firstOffset int64, lastOffset int64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	first, err := r.client.XRangeN(ctx, r.streamKey(), "-", "+", 1).Result()
+	if err != nil {
+		return -1, -1, err
+	}
+	last, err := r.client.XRevRangeN(ctx, r.streamKey(), "+", "-", 1).Result()
+	if err != nil {
+		return -1, -1, err
+	}
+	if len(first) == 0 || len(last) == 0 {
+		return 0, -1, nil
+	}
+	firstOffset, err = redisStreamOffset(first[0].ID)
+	if err != nil {
+		return -1, -1, err
+	}
+	lastOffset, err = redisStreamOffset(last[0].ID)
+	if err != nil {
+		return -1, -1, err
+	}
+	return firstOffset, lastOffset, nil
+}
+
+func (r *RedisBus) Broadcast(ctx context.Context, info *pb.BlockInfo) error {
+	value, err := proto.Marshal(info)
+	if err != nil {
+		return err
+	}
+	offset := r.writerLastOffset + 1
+	err = r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.streamKey(),
+		ID:     redisStreamID(offset),
+		Values: []interface{}{redisInfoField, value},
+	}).Err()
+	if err != nil {
+		return err
+	}
+	r.incrementLastWriterOffset()
+	utils.Logger().Info("broadcast", zap.Any("offset", offset), zap.Any("writerLastOffset", r.writerLastOffset))
+	return nil
+}
+
+func (r *RedisBus) Fetch(ctx context.Context) (infos []*pb.BlockInfo, err error) {
+	return r.FetchStart(ctx, r.readerLastOffset+1)
+}
+
+// FetchStart blocks up to KafkaMaxWait for the messages starting at start.
+func (r *RedisBus) FetchStart(ctx context.Context, start int64) (infos []*pb.BlockInfo, err error) {
+	if start < 0 {
+		start = 0
+	}
+	startTime := time.Now()
+	// XREAD returns the entries with an id greater than the given one.
+	streams, err := r.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{r.streamKey(), redisStreamID(start - 1)},
+		Count:   redisFetchCount,
+		Block:   KafkaMaxWait,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		utils.Logger().Error("fetch", zap.Any("err", err))
+		return nil, err
+	}
+	r.kafkaMetrics.ObserveLatency(r.topic, float64(time.Since(startTime).Milliseconds()))
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			value, ok := msg.Values[redisInfoField].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid stream entry: %s", msg.ID)
+			}
+			info := &pb.BlockInfo{}
+			if err := proto.Unmarshal([]byte(value), info); err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func (r *RedisBus) CommitOffset(ctx context.Context, groupID string, offset int64) error {
+	return r.client.HSet(ctx, r.groupsKey(), groupID, strconv.FormatInt(offset, 10)).Err()
+}
+
+func (r *RedisBus) CommittedOffset(ctx context.Context, groupID string) (offset int64, ok bool, err error) {
+	offset, err = r.client.HGet(ctx, r.groupsKey(), groupID).Int64()
+	if errors.Is(err, redis.Nil) {
+		return -1, false, nil
+	}
+	if err != nil {
+		return -1, false, err
+	}
+	return offset, true, nil
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprom "github.com/prometheus/client_golang/prometheus"
+)
+
+type CacheMetrics struct {
+	CacheHits      *prometheus.Counter
+	CacheMisses    *prometheus.Counter
+	CacheCoalesced *prometheus.Counter
+	CacheEvictions *prometheus.Counter
+	CacheBytes     *prometheus.Gauge
+}
+
+var (
+	cacheMetrics     *CacheMetrics
+	cacheMetricsOnce sync.Once
+)
+
+// NewCacheMetrics returns the process wide CacheMetrics, caches are told
+// apart by their name.
+func NewCacheMetrics() *CacheMetrics {
+	cacheMetricsOnce.Do(func() {
+		cacheMetrics = &CacheMetrics{
+			CacheHits: prometheus.NewCounterFrom(stdprom.CounterOpts{
+				Name: "cache_hits",
+				Help: "Cache lookups served from the cache",
+			}, []string{"cache"}),
+			CacheMisses: prometheus.NewCounterFrom(stdprom.CounterOpts{
+				Name: "cache_misses",
+				Help: "Cache lookups which fetched the value",
+			}, []string{"cache"}),
+			CacheCoalesced: prometheus.NewCounterFrom(stdprom.CounterOpts{
+				Name: "cache_coalesced",
+				Help: "Cache lookups served by the fetch of a concurrent lookup",
+			}, []string{"cache"}),
+			CacheEvictions: prometheus.NewCounterFrom(stdprom.CounterOpts{
+				Name: "cache_evictions",
+				Help: "Cache entries evicted, by reason bytes, height or failed",
+			}, []string{"cache", "reason"}),
+			CacheBytes: prometheus.NewGaugeFrom(stdprom.GaugeOpts{
+				Name: "cache_bytes",
+				Help: "Bytes of the values in the cache",
+			}, []string{"cache"}),
+		}
+	})
+	return cacheMetrics
+}
+
+func (m *CacheMetrics) IncreaseHits(cache string) {
+	m.CacheHits.With("cache", cache).Add(1)
+}
+
+func (m *CacheMetrics) IncreaseMisses(cache string) {
+	m.CacheMisses.With("cache", cache).Add(1)
+}
+
+func (m *CacheMetrics) IncreaseCoalesced(cache string) {
+	m.CacheCoalesced.With("cache", cache).Add(1)
+}
+
+func (m *CacheMetrics) IncreaseEvictions(cache, reason string) {
+	m.CacheEvictions.With("cache", cache, "reason", reason).Add(1)
+}
+
+func (m *CacheMetrics) SetBytes(cache string, bytes int64) {
+	m.CacheBytes.With("cache", cache).Set(float64(bytes))
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprom "github.com/prometheus/client_golang/prometheus"
+)
+
+type GCMetrics struct {
+	GCRemovedFiles *prometheus.Counter
+	GCRemovedBytes *prometheus.Counter
+	GCCutoff       *prometheus.Gauge
+}
+
+var (
+	gcMetrics     *GCMetrics
+	gcMetricsOnce sync.Once
+)
+
+// NewGCMetrics returns the process wide GCMetrics.
+func NewGCMetrics() *GCMetrics {
+	gcMetricsOnce.Do(func() {
+		gcMetrics = newGCMetrics()
+	})
+	return gcMetrics
+}
+
+func newGCMetrics() *GCMetrics {
+	return &GCMetrics{
+		GCRemovedFiles: prometheus.NewCounterFrom(stdprom.CounterOpts{
+			Name: "gc_removed_files",
+			Help: "Number of block objects removed by gc",
+		}, []string{"prefix", "dry_run"}),
+		GCRemovedBytes: prometheus.NewCounterFrom(stdprom.CounterOpts{
+			Name: "gc_removed_bytes",
+			Help: "Bytes of block objects reclaimed by gc",
+		}, []string{"prefix", "dry_run"}),
+		GCCutoff: prometheus.NewGaugeFrom(stdprom.GaugeOpts{
+			Name: "gc_cutoff",
+			Help: "Block number below which gc removes block objects",
+		}, []string{"prefix"}),
+	}
+}
+
+func (m *GCMetrics) IncreaseRemoved(prefix string, dryRun bool, files, bytes int64) {
+	m.GCRemovedFiles.With("prefix", prefix, "dry_run", strconv.FormatBool(dryRun)).Add(float64(files))
+	m.GCRemovedBytes.With("prefix", prefix, "dry_run", strconv.FormatBool(dryRun)).Add(float64(bytes))
+}
+
+func (m *GCMetrics) SetCutoff(prefix string, blockNum int64) {
+	m.GCCutoff.With("prefix", prefix).Set(float64(blockNum))
+}
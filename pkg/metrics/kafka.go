@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/go-kit/kit/metrics/prometheus"
 	stdprom "github.com/prometheus/client_golang/prometheus"
 )
@@ -12,7 +14,21 @@ type KafkaMetrics struct {
 	KafkaLatency      *prometheus.Histogram
 }
 
+var (
+	kafkaMetrics     *KafkaMetrics
+	kafkaMetricsOnce sync.Once
+)
+
+// NewKafkaMetrics returns the process wide KafkaMetrics, several KafkaClients
+// share the collectors labelled by topic.
 func NewKafkaMetrics() *KafkaMetrics {
+	kafkaMetricsOnce.Do(func() {
+		kafkaMetrics = newKafkaMetrics()
+	})
+	return kafkaMetrics
+}
+
+func newKafkaMetrics() *KafkaMetrics {
 	return &KafkaMetrics{
 		KafkaWriterOffset: prometheus.NewCounterFrom(stdprom.CounterOpts{
 			Name: "kafka_writer_offset",
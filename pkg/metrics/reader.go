@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprom "github.com/prometheus/client_golang/prometheus"
+)
+
+type ReaderMetrics struct {
+	PrefetchOccupancy *prometheus.Gauge
+	ReorgDepth        *prometheus.Histogram
+	BadSignatures     *prometheus.Counter
+}
+
+var (
+	readerMetrics     *ReaderMetrics
+	readerMetricsOnce sync.Once
+)
+
+// NewReaderMetrics returns the process wide ReaderMetrics.
+func NewReaderMetrics() *ReaderMetrics {
+	readerMetricsOnce.Do(func() {
+		readerMetrics = &ReaderMetrics{
+			PrefetchOccupancy: prometheus.NewGaugeFrom(stdprom.GaugeOpts{
+				Name: "reader_prefetch_occupancy",
+				Help: "Reader blocks downloaded or downloading but not yet applied",
+			}, []string{"chain"}),
+			ReorgDepth: prometheus.NewHistogramFrom(stdprom.HistogramOpts{
+				Name:    "reader_reorg_depth",
+				Help:    "Reader blocks rolled back by a chain reorg",
+				Buckets: stdprom.ExponentialBuckets(1, 2, 10),
+			}, []string{"chain"}),
+			BadSignatures: prometheus.NewCounterFrom(stdprom.CounterOpts{
+				Name: "reader_bad_signatures",
+				Help: "Reader blocks rejected as unsigned or mis-signed",
+			}, []string{"chain"}),
+		}
+	})
+	return readerMetrics
+}
+
+func (m *ReaderMetrics) SetPrefetchOccupancy(chain string, occupancy int64) {
+	m.PrefetchOccupancy.With("chain", chain).Set(float64(occupancy))
+}
+
+func (m *ReaderMetrics) ObserveReorgDepth(chain string, depth int64) {
+	m.ReorgDepth.With("chain", chain).Observe(float64(depth))
+}
+
+func (m *ReaderMetrics) IncreaseBadSignatures(chain string) {
+	m.BadSignatures.With("chain", chain).Add(1)
+}
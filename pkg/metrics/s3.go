@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/go-kit/kit/metrics/prometheus"
 	stdprom "github.com/prometheus/client_golang/prometheus"
 )
@@ -10,9 +12,28 @@ type S3Metrics struct {
 	S3ReadLatency  *prometheus.Histogram
 	S3WriteSize    *prometheus.Counter
 	S3ReadSize     *prometheus.Counter
+
+	S3ChecksumMismatch *prometheus.Counter
+	S3ProxyErrors      *prometheus.Counter
+	S3Hedges           *prometheus.Counter
+	S3QueueWait        *prometheus.Histogram
 }
 
+var (
+	s3Metrics     *S3Metrics
+	s3MetricsOnce sync.Once
+)
+
+// NewS3Metrics returns the process wide S3Metrics, the collectors are
+// registered once so the proxy and its clients can live in one process.
 func NewS3Metrics() *S3Metrics {
+	s3MetricsOnce.Do(func() {
+		s3Metrics = newS3Metrics()
+	})
+	return s3Metrics
+}
+
+func newS3Metrics() *S3Metrics {
 	return &S3Metrics{
 		S3WriteLatency: prometheus.NewHistogramFrom(stdprom.HistogramOpts{
 			Name:    "s3_write_latency",
@@ -32,6 +53,23 @@ func NewS3Metrics() *S3Metrics {
 			Name: "s3_read_size",
 			Help: "S3 read size",
 		}, []string{"bucket"}),
+		S3ChecksumMismatch: prometheus.NewCounterFrom(stdprom.CounterOpts{
+			Name: "s3_checksum_mismatch",
+			Help: "S3 block checksum mismatch count",
+		}, []string{"bucket"}),
+		S3ProxyErrors: prometheus.NewCounterFrom(stdprom.CounterOpts{
+			Name: "s3_proxy_errors",
+			Help: "S3 proxy request errors failed over to the next proxy",
+		}, []string{"addr"}),
+		S3Hedges: prometheus.NewCounterFrom(stdprom.CounterOpts{
+			Name: "s3_hedges",
+			Help: "S3 reads hedged by a second read",
+		}, []string{"bucket"}),
+		S3QueueWait: prometheus.NewHistogramFrom(stdprom.HistogramOpts{
+			Name:    "s3_queue_wait",
+			Help:    "S3 read wait for a free slot",
+			Buckets: getLoadTimeBucket(),
+		}, []string{"bucket"}),
 	}
 }
 
@@ -50,3 +88,19 @@ func (m *S3Metrics) IncreaseWriteSize(bucket string, size int64) {
 func (m *S3Metrics) IncreaseReadSize(bucket string, size int64) {
 	m.S3ReadSize.With("bucket", bucket).Add(float64(size))
 }
+
+func (m *S3Metrics) IncreaseChecksumMismatch(bucket string) {
+	m.S3ChecksumMismatch.With("bucket", bucket).Add(1)
+}
+
+func (m *S3Metrics) IncreaseProxyErrors(addr string) {
+	m.S3ProxyErrors.With("addr", addr).Add(1)
+}
+
+func (m *S3Metrics) IncreaseHedges(bucket string) {
+	m.S3Hedges.With("bucket", bucket).Add(1)
+}
+
+func (m *S3Metrics) ObserveQueueWait(bucket string, wait float64) {
+	m.S3QueueWait.With("bucket", bucket).Observe(wait)
+}
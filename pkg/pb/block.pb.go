@@ -69,20 +69,80 @@ func (BlockInfo_BlockType) EnumDescriptor() ([]byte, []int) {
 	return file_pkg_pb_block_proto_rawDescGZIP(), []int{0, 0}
 }
 
+// NONE is an unrecorded codec, the objects put before codecs were
+// recorded are recognized from their data.
+type BlockInfo_Compression int32
+
+const (
+	BlockInfo_NONE         BlockInfo_Compression = 0
+	BlockInfo_ZSTD         BlockInfo_Compression = 1
+	BlockInfo_SNAPPY       BlockInfo_Compression = 2
+	BlockInfo_UNCOMPRESSED BlockInfo_Compression = 3
+)
+
+// Enum value maps for BlockInfo_Compression.
+var (
+	BlockInfo_Compression_name = map[int32]string{
+		0: "NONE",
+		1: "ZSTD",
+		2: "SNAPPY",
+		3: "UNCOMPRESSED",
+	}
+	BlockInfo_Compression_value = map[string]int32{
+		"NONE":         0,
+		"ZSTD":         1,
+		"SNAPPY":       2,
+		"UNCOMPRESSED": 3,
+	}
+)
+
+func (x BlockInfo_Compression) Enum() *BlockInfo_Compression {
+	p := new(BlockInfo_Compression)
+	*p = x
+	return p
+}
+
+func (x BlockInfo_Compression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BlockInfo_Compression) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_pb_block_proto_enumTypes[1].Descriptor()
+}
+
+func (BlockInfo_Compression) Type() protoreflect.EnumType {
+	return &file_pkg_pb_block_proto_enumTypes[1]
+}
+
+func (x BlockInfo_Compression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BlockInfo_Compression.Descriptor instead.
+func (BlockInfo_Compression) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{0, 1}
+}
+
 type BlockInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ChainId   string              `protobuf:"bytes,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
-	Env       string              `protobuf:"bytes,2,opt,name=env,proto3" json:"env,omitempty"`
-	Role      string              `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
-	BlockNum  int64               `protobuf:"varint,4,opt,name=block_num,json=blockNum,proto3" json:"block_num,omitempty"`
-	BlockHash string              `protobuf:"bytes,5,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
-	BlockRoot string              `protobuf:"bytes,6,opt,name=block_root,json=blockRoot,proto3" json:"block_root,omitempty"`
-	MsgOffset int64               `protobuf:"varint,7,opt,name=msg_offset,json=msgOffset,proto3" json:"msg_offset,omitempty"`
-	BlockType BlockInfo_BlockType `protobuf:"varint,8,opt,name=block_type,json=blockType,proto3,enum=pb.BlockInfo_BlockType" json:"block_type,omitempty"`
-	BlockSize int64               `protobuf:"varint,9,opt,name=block_size,json=blockSize,proto3" json:"block_size,omitempty"`
+	ChainId     string                `protobuf:"bytes,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Env         string                `protobuf:"bytes,2,opt,name=env,proto3" json:"env,omitempty"`
+	Role        string                `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	BlockNum    int64                 `protobuf:"varint,4,opt,name=block_num,json=blockNum,proto3" json:"block_num,omitempty"`
+	BlockHash   string                `protobuf:"bytes,5,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	BlockRoot   string                `protobuf:"bytes,6,opt,name=block_root,json=blockRoot,proto3" json:"block_root,omitempty"`
+	MsgOffset   int64                 `protobuf:"varint,7,opt,name=msg_offset,json=msgOffset,proto3" json:"msg_offset,omitempty"`
+	BlockType   BlockInfo_BlockType   `protobuf:"varint,8,opt,name=block_type,json=blockType,proto3,enum=pb.BlockInfo_BlockType" json:"block_type,omitempty"`
+	BlockSize   int64                 `protobuf:"varint,9,opt,name=block_size,json=blockSize,proto3" json:"block_size,omitempty"`
+	Compression BlockInfo_Compression `protobuf:"varint,10,opt,name=compression,proto3,enum=pb.BlockInfo_Compression" json:"compression,omitempty"`
+	// sha256 of the header and data objects stored in s3.
+	HeaderChecksum []byte `protobuf:"bytes,11,opt,name=header_checksum,json=headerChecksum,proto3" json:"header_checksum,omitempty"`
+	DataChecksum   []byte `protobuf:"bytes,12,opt,name=data_checksum,json=dataChecksum,proto3" json:"data_checksum,omitempty"`
+	// ed25519 signature of the writer over the block and its content digest.
+	Signature []byte `protobuf:"bytes,13,opt,name=signature,proto3" json:"signature,omitempty"`
 }
 
 func (x *BlockInfo) Reset() {
@@ -180,6 +240,34 @@ func (x *BlockInfo) GetBlockSize() int64 {
 	return 0
 }
 
+func (x *BlockInfo) GetCompression() BlockInfo_Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return BlockInfo_NONE
+}
+
+func (x *BlockInfo) GetHeaderChecksum() []byte {
+	if x != nil {
+		return x.HeaderChecksum
+	}
+	return nil
+}
+
+func (x *BlockInfo) GetDataChecksum() []byte {
+	if x != nil {
+		return x.DataChecksum
+	}
+	return nil
+}
+
+func (x *BlockInfo) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
 type Data struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -290,6 +378,70 @@ func (x *Block) GetBatchItems() []*Data {
 	return nil
 }
 
+// UndoLog restores the DBs to the state before a block was applied.
+type UndoLog struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Info       *BlockInfo `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Prev       *BlockInfo `protobuf:"bytes,2,opt,name=prev,proto3" json:"prev,omitempty"`
+	BatchItems []*Data    `protobuf:"bytes,3,rep,name=batch_items,json=batchItems,proto3" json:"batch_items,omitempty"`
+}
+
+func (x *UndoLog) Reset() {
+	*x = UndoLog{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_block_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UndoLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndoLog) ProtoMessage() {}
+
+func (x *UndoLog) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_block_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndoLog.ProtoReflect.Descriptor instead.
+func (*UndoLog) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UndoLog) GetInfo() *BlockInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *UndoLog) GetPrev() *BlockInfo {
+	if x != nil {
+		return x.Prev
+	}
+	return nil
+}
+
+func (x *UndoLog) GetBatchItems() []*Data {
+	if x != nil {
+		return x.BatchItems
+	}
+	return nil
+}
+
 type KV struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -302,7 +454,7 @@ type KV struct {
 func (x *KV) Reset() {
 	*x = KV{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_block_proto_msgTypes[3]
+		mi := &file_pkg_pb_block_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -315,7 +467,7 @@ func (x *KV) String() string {
 func (*KV) ProtoMessage() {}
 
 func (x *KV) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_block_proto_msgTypes[3]
+	mi := &file_pkg_pb_block_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -328,7 +480,7 @@ func (x *KV) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KV.ProtoReflect.Descriptor instead.
 func (*KV) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_block_proto_rawDescGZIP(), []int{3}
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *KV) GetKey() []byte {
@@ -357,7 +509,7 @@ type Account struct {
 func (x *Account) Reset() {
 	*x = Account{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_block_proto_msgTypes[4]
+		mi := &file_pkg_pb_block_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -370,7 +522,7 @@ func (x *Account) String() string {
 func (*Account) ProtoMessage() {}
 
 func (x *Account) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_block_proto_msgTypes[4]
+	mi := &file_pkg_pb_block_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +535,7 @@ func (x *Account) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Account.ProtoReflect.Descriptor instead.
 func (*Account) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_block_proto_rawDescGZIP(), []int{4}
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Account) GetAddress() string {
@@ -411,7 +563,7 @@ type Accounts struct {
 func (x *Accounts) Reset() {
 	*x = Accounts{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_block_proto_msgTypes[5]
+		mi := &file_pkg_pb_block_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -424,7 +576,7 @@ func (x *Accounts) String() string {
 func (*Accounts) ProtoMessage() {}
 
 func (x *Accounts) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_block_proto_msgTypes[5]
+	mi := &file_pkg_pb_block_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -437,7 +589,7 @@ func (x *Accounts) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Accounts.ProtoReflect.Descriptor instead.
 func (*Accounts) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_block_proto_rawDescGZIP(), []int{5}
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Accounts) GetAccounts() []*Account {
@@ -461,7 +613,7 @@ type DBInfo struct {
 func (x *DBInfo) Reset() {
 	*x = DBInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_block_proto_msgTypes[6]
+		mi := &file_pkg_pb_block_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -474,7 +626,7 @@ func (x *DBInfo) String() string {
 func (*DBInfo) ProtoMessage() {}
 
 func (x *DBInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_block_proto_msgTypes[6]
+	mi := &file_pkg_pb_block_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -487,7 +639,7 @@ func (x *DBInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DBInfo.ProtoReflect.Descriptor instead.
 func (*DBInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_block_proto_rawDescGZIP(), []int{6}
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DBInfo) GetId() int32 {
@@ -529,7 +681,7 @@ type DBInfoList struct {
 func (x *DBInfoList) Reset() {
 	*x = DBInfoList{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_pb_block_proto_msgTypes[7]
+		mi := &file_pkg_pb_block_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -542,7 +694,7 @@ func (x *DBInfoList) String() string {
 func (*DBInfoList) ProtoMessage() {}
 
 func (x *DBInfoList) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_pb_block_proto_msgTypes[7]
+	mi := &file_pkg_pb_block_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -555,7 +707,7 @@ func (x *DBInfoList) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DBInfoList.ProtoReflect.Descriptor instead.
 func (*DBInfoList) Descriptor() ([]byte, []int) {
-	return file_pkg_pb_block_proto_rawDescGZIP(), []int{7}
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DBInfoList) GetDbInfos() []*DBInfo {
@@ -565,11 +717,216 @@ func (x *DBInfoList) GetDbInfos() []*DBInfo {
 	return nil
 }
 
+// Snapshot is the manifest of a consistent export of all DBs of a chain
+// taken after the block of info was applied. A snapshot of a checkpoint
+// chain with a parent only has the keys changed since its parent, deleted
+// keys as deletions.
+type Snapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Info       *BlockInfo    `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Dbs        []*SnapshotDB `protobuf:"bytes,2,rep,name=dbs,proto3" json:"dbs,omitempty"`
+	CreateTime int64         `protobuf:"varint,3,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// manifest key of the snapshot.
+	Key string `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	// manifest key of the previous checkpoint, empty for a full one.
+	Parent string `protobuf:"bytes,5,opt,name=parent,proto3" json:"parent,omitempty"`
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_block_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_block_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Snapshot) GetInfo() *BlockInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *Snapshot) GetDbs() []*SnapshotDB {
+	if x != nil {
+		return x.Dbs
+	}
+	return nil
+}
+
+func (x *Snapshot) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *Snapshot) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Snapshot) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+type SnapshotDB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbInfo *DBInfo          `protobuf:"bytes,1,opt,name=db_info,json=dbInfo,proto3" json:"db_info,omitempty"`
+	Chunks []*SnapshotChunk `protobuf:"bytes,2,rep,name=chunks,proto3" json:"chunks,omitempty"`
+}
+
+func (x *SnapshotDB) Reset() {
+	*x = SnapshotDB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_block_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotDB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotDB) ProtoMessage() {}
+
+func (x *SnapshotDB) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_block_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotDB.ProtoReflect.Descriptor instead.
+func (*SnapshotDB) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SnapshotDB) GetDbInfo() *DBInfo {
+	if x != nil {
+		return x.DbInfo
+	}
+	return nil
+}
+
+func (x *SnapshotDB) GetChunks() []*SnapshotChunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+// SnapshotChunk is a dumped batch of key values of a DB.
+type SnapshotChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// sha256 of the object stored in s3.
+	Checksum    []byte                `protobuf:"bytes,2,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	Compression BlockInfo_Compression `protobuf:"varint,3,opt,name=compression,proto3,enum=pb.BlockInfo_Compression" json:"compression,omitempty"`
+}
+
+func (x *SnapshotChunk) Reset() {
+	*x = SnapshotChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_block_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunk) ProtoMessage() {}
+
+func (x *SnapshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_block_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunk.ProtoReflect.Descriptor instead.
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_block_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SnapshotChunk) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SnapshotChunk) GetChecksum() []byte {
+	if x != nil {
+		return x.Checksum
+	}
+	return nil
+}
+
+func (x *SnapshotChunk) GetCompression() BlockInfo_Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return BlockInfo_NONE
+}
+
 var File_pkg_pb_block_proto protoreflect.FileDescriptor
 
 var file_pkg_pb_block_proto_rawDesc = []byte{
 	0x0a, 0x12, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0xcd, 0x02, 0x0a, 0x09, 0x42, 0x6c, 0x6f,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0xb7, 0x04, 0x0a, 0x09, 0x42, 0x6c, 0x6f,
 	0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x5f,
 	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49,
 	0x64, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
@@ -587,41 +944,86 @@ var file_pkg_pb_block_proto_rawDesc = []byte{
 	0x49, 0x6e, 0x66, 0x6f, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09,
 	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f,
 	0x63, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x2e, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e,
-	0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x41, 0x54, 0x41, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06,
-	0x48, 0x45, 0x41, 0x44, 0x45, 0x52, 0x10, 0x02, 0x22, 0x2a, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61,
-	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64,
-	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x22, 0x55, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x21, 0x0a,
-	0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62,
-	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f,
-	0x12, 0x29, 0x0a, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x52,
-	0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x2c, 0x0a, 0x02, 0x4b,
-	0x56, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03,
-	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x37, 0x0a, 0x07, 0x41, 0x63, 0x63,
-	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12,
-	0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61,
-	0x74, 0x61, 0x22, 0x33, 0x0a, 0x08, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x27,
-	0x0a, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x08, 0x61,
-	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x22, 0x63, 0x0a, 0x06, 0x44, 0x42, 0x49, 0x6e, 0x66,
-	0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69,
-	0x64, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x54, 0x79, 0x70, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62,
-	0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x50,
-	0x61, 0x74, 0x68, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x4d, 0x65, 0x74, 0x61, 0x22, 0x33, 0x0a, 0x0a,
-	0x44, 0x42, 0x49, 0x6e, 0x66, 0x6f, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x08, 0x64, 0x62,
-	0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70,
-	0x62, 0x2e, 0x44, 0x42, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07, 0x64, 0x62, 0x49, 0x6e, 0x66, 0x6f,
-	0x73, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x44, 0x65, 0x42, 0x61, 0x6e, 0x6b, 0x44, 0x65, 0x46, 0x69, 0x2f, 0x64, 0x62, 0x2d, 0x72, 0x65,
-	0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e,
+	0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x2e, 0x43, 0x6f, 0x6d,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x12, 0x23,
+	0x0a, 0x0d, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x22, 0x2e, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b,
+	0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x44,
+	0x41, 0x54, 0x41, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x48, 0x45, 0x41, 0x44, 0x45, 0x52, 0x10,
+	0x02, 0x22, 0x3f, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x5a, 0x53,
+	0x54, 0x44, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x4e, 0x41, 0x50, 0x50, 0x59, 0x10, 0x02,
+	0x12, 0x10, 0x0a, 0x0c, 0x55, 0x4e, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x45, 0x44,
+	0x10, 0x03, 0x22, 0x2a, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x55,
+	0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x21, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x29, 0x0a, 0x0b, 0x62, 0x61,
+	0x74, 0x63, 0x68, 0x5f, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x08, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68,
+	0x49, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x7a, 0x0a, 0x07, 0x55, 0x6e, 0x64, 0x6f, 0x4c, 0x6f, 0x67,
+	0x12, 0x21, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d,
+	0x2e, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69,
+	0x6e, 0x66, 0x6f, 0x12, 0x21, 0x0a, 0x04, 0x70, 0x72, 0x65, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x04, 0x70, 0x72, 0x65, 0x76, 0x12, 0x29, 0x0a, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f,
+	0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62,
+	0x2e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d,
+	0x73, 0x22, 0x2c, 0x0a, 0x02, 0x4b, 0x56, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22,
+	0x37, 0x0a, 0x07, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x41, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x22, 0x33, 0x0a, 0x08, 0x41, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x12, 0x27, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x52, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x22, 0x63, 0x0a,
+	0x06, 0x44, 0x42, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x64, 0x62, 0x50, 0x61, 0x74, 0x68, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f,
+	0x6d, 0x65, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x4d, 0x65,
+	0x74, 0x61, 0x22, 0x33, 0x0a, 0x0a, 0x44, 0x42, 0x49, 0x6e, 0x66, 0x6f, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x25, 0x0a, 0x08, 0x64, 0x62, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x42, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07,
+	0x64, 0x62, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x22, 0x9a, 0x01, 0x0a, 0x08, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x12, 0x21, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66,
+	0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x03, 0x64, 0x62, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x44, 0x42, 0x52, 0x03, 0x64, 0x62, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06,
+	0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61,
+	0x72, 0x65, 0x6e, 0x74, 0x22, 0x5c, 0x0a, 0x0a, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x44, 0x42, 0x12, 0x23, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x42, 0x49, 0x6e, 0x66, 0x6f, 0x52,
+	0x06, 0x64, 0x62, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x29, 0x0a, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x52, 0x06, 0x63, 0x68, 0x75, 0x6e,
+	0x6b, 0x73, 0x22, 0x7a, 0x0a, 0x0d, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x68,
+	0x75, 0x6e, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75,
+	0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75,
+	0x6d, 0x12, 0x3b, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x24,
+	0x5a, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x44, 0x65, 0x42,
+	0x61, 0x6e, 0x6b, 0x44, 0x65, 0x46, 0x69, 0x2f, 0x6e, 0x6f, 0x64, 0x65, 0x78, 0x2f, 0x70, 0x6b,
+	0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -636,30 +1038,44 @@ func file_pkg_pb_block_proto_rawDescGZIP() []byte {
 	return file_pkg_pb_block_proto_rawDescData
 }
 
-var file_pkg_pb_block_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_pb_block_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_pkg_pb_block_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pkg_pb_block_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_pkg_pb_block_proto_goTypes = []interface{}{
-	(BlockInfo_BlockType)(0), // 0: pb.BlockInfo.BlockType
-	(*BlockInfo)(nil),        // 1: pb.BlockInfo
-	(*Data)(nil),             // 2: pb.Data
-	(*Block)(nil),            // 3: pb.Block
-	(*KV)(nil),               // 4: pb.KV
-	(*Account)(nil),          // 5: pb.Account
-	(*Accounts)(nil),         // 6: pb.Accounts
-	(*DBInfo)(nil),           // 7: pb.DBInfo
-	(*DBInfoList)(nil),       // 8: pb.DBInfoList
+	(BlockInfo_BlockType)(0),   // 0: pb.BlockInfo.BlockType
+	(BlockInfo_Compression)(0), // 1: pb.BlockInfo.Compression
+	(*BlockInfo)(nil),          // 2: pb.BlockInfo
+	(*Data)(nil),               // 3: pb.Data
+	(*Block)(nil),              // 4: pb.Block
+	(*UndoLog)(nil),            // 5: pb.UndoLog
+	(*KV)(nil),                 // 6: pb.KV
+	(*Account)(nil),            // 7: pb.Account
+	(*Accounts)(nil),           // 8: pb.Accounts
+	(*DBInfo)(nil),             // 9: pb.DBInfo
+	(*DBInfoList)(nil),         // 10: pb.DBInfoList
+	(*Snapshot)(nil),           // 11: pb.Snapshot
+	(*SnapshotDB)(nil),         // 12: pb.SnapshotDB
+	(*SnapshotChunk)(nil),      // 13: pb.SnapshotChunk
 }
 var file_pkg_pb_block_proto_depIdxs = []int32{
-	0, // 0: pb.BlockInfo.block_type:type_name -> pb.BlockInfo.BlockType
-	1, // 1: pb.Block.info:type_name -> pb.BlockInfo
-	2, // 2: pb.Block.batch_items:type_name -> pb.Data
-	5, // 3: pb.Accounts.accounts:type_name -> pb.Account
-	7, // 4: pb.DBInfoList.db_infos:type_name -> pb.DBInfo
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	0,  // 0: pb.BlockInfo.block_type:type_name -> pb.BlockInfo.BlockType
+	1,  // 1: pb.BlockInfo.compression:type_name -> pb.BlockInfo.Compression
+	2,  // 2: pb.Block.info:type_name -> pb.BlockInfo
+	3,  // 3: pb.Block.batch_items:type_name -> pb.Data
+	2,  // 4: pb.UndoLog.info:type_name -> pb.BlockInfo
+	2,  // 5: pb.UndoLog.prev:type_name -> pb.BlockInfo
+	3,  // 6: pb.UndoLog.batch_items:type_name -> pb.Data
+	7,  // 7: pb.Accounts.accounts:type_name -> pb.Account
+	9,  // 8: pb.DBInfoList.db_infos:type_name -> pb.DBInfo
+	2,  // 9: pb.Snapshot.info:type_name -> pb.BlockInfo
+	12, // 10: pb.Snapshot.dbs:type_name -> pb.SnapshotDB
+	9,  // 11: pb.SnapshotDB.db_info:type_name -> pb.DBInfo
+	13, // 12: pb.SnapshotDB.chunks:type_name -> pb.SnapshotChunk
+	1,  // 13: pb.SnapshotChunk.compression:type_name -> pb.BlockInfo.Compression
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_pkg_pb_block_proto_init() }
@@ -705,7 +1121,7 @@ func file_pkg_pb_block_proto_init() {
 			}
 		}
 		file_pkg_pb_block_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*KV); i {
+			switch v := v.(*UndoLog); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -717,7 +1133,7 @@ func file_pkg_pb_block_proto_init() {
 			}
 		}
 		file_pkg_pb_block_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Account); i {
+			switch v := v.(*KV); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -729,7 +1145,7 @@ func file_pkg_pb_block_proto_init() {
 			}
 		}
 		file_pkg_pb_block_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Accounts); i {
+			switch v := v.(*Account); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -741,7 +1157,7 @@ func file_pkg_pb_block_proto_init() {
 			}
 		}
 		file_pkg_pb_block_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DBInfo); i {
+			switch v := v.(*Accounts); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -753,6 +1169,18 @@ func file_pkg_pb_block_proto_init() {
 			}
 		}
 		file_pkg_pb_block_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DBInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_block_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DBInfoList); i {
 			case 0:
 				return &v.state
@@ -764,14 +1192,50 @@ func file_pkg_pb_block_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_pb_block_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Snapshot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_block_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnapshotDB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_block_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnapshotChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_pb_block_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   8,
+			NumEnums:      2,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
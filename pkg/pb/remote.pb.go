@@ -1572,6 +1572,100 @@ func (x *SyncyReply) GetData() *Block {
 	return nil
 }
 
+type RewindRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockNum int64 `protobuf:"varint,1,opt,name=block_num,json=blockNum,proto3" json:"block_num,omitempty"`
+}
+
+func (x *RewindRequest) Reset() {
+	*x = RewindRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_remote_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RewindRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewindRequest) ProtoMessage() {}
+
+func (x *RewindRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_remote_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewindRequest.ProtoReflect.Descriptor instead.
+func (*RewindRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_remote_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RewindRequest) GetBlockNum() int64 {
+	if x != nil {
+		return x.BlockNum
+	}
+	return 0
+}
+
+type RewindReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Info *BlockInfo `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+}
+
+func (x *RewindReply) Reset() {
+	*x = RewindReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_pb_remote_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RewindReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewindReply) ProtoMessage() {}
+
+func (x *RewindReply) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_pb_remote_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewindReply.ProtoReflect.Descriptor instead.
+func (*RewindReply) Descriptor() ([]byte, []int) {
+	return file_pkg_pb_remote_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RewindReply) GetInfo() *BlockInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
 var File_pkg_pb_remote_proto protoreflect.FileDescriptor
 
 var file_pkg_pb_remote_proto_rawDesc = []byte{
@@ -1681,8 +1775,14 @@ var file_pkg_pb_remote_proto_rawDesc = []byte{
 	0x70, 0x6c, 0x79, 0x22, 0x0d, 0x0a, 0x0b, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x22, 0x2b, 0x0a, 0x0a, 0x53, 0x79, 0x6e, 0x63, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79,
 	0x12, 0x1d, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09,
-	0x2e, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x32,
-	0xc5, 0x04, 0x0a, 0x06, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x6f, 0x70,
+	0x2e, 0x70, 0x62, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22,
+	0x2c, 0x0a, 0x0d, 0x52, 0x65, 0x77, 0x69, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1b, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x22, 0x30, 0x0a,
+	0x0b, 0x52, 0x65, 0x77, 0x69, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x21, 0x0a, 0x04,
+	0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x32,
+	0xf5, 0x04, 0x0a, 0x06, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x6f, 0x70,
 	0x65, 0x6e, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x70,
 	0x6c, 0x79, 0x22, 0x00, 0x12, 0x25, 0x0a, 0x03, 0x67, 0x65, 0x74, 0x12, 0x0e, 0x2e, 0x70, 0x62,
@@ -1718,10 +1818,13 @@ var file_pkg_pb_remote_proto_rawDesc = []byte{
 	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x2b, 0x0a, 0x04, 0x73, 0x79,
 	0x6e, 0x63, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x79, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x2e, 0x0a, 0x06, 0x72, 0x65, 0x77, 0x69, 0x6e,
+	0x64, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x77, 0x69, 0x6e, 0x64, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x77, 0x69, 0x6e, 0x64,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x24, 0x5a, 0x22, 0x67, 0x69, 0x74, 0x68, 0x75,
 	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x44, 0x65, 0x42, 0x61, 0x6e, 0x6b, 0x44, 0x65, 0x46, 0x69,
-	0x2f, 0x64, 0x62, 0x2d, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x70,
-	0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2f, 0x6e, 0x6f, 0x64, 0x65, 0x78, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1736,7 +1839,7 @@ func file_pkg_pb_remote_proto_rawDescGZIP() []byte {
 	return file_pkg_pb_remote_proto_rawDescData
 }
 
-var file_pkg_pb_remote_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_pkg_pb_remote_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_pkg_pb_remote_proto_goTypes = []interface{}{
 	(*OpenRequest)(nil),         // 0: pb.OpenRequest
 	(*OpenReply)(nil),           // 1: pb.OpenReply
@@ -1766,11 +1869,14 @@ var file_pkg_pb_remote_proto_goTypes = []interface{}{
 	(*SnapshotReply)(nil),       // 25: pb.SnapshotReply
 	(*SyncRequest)(nil),         // 26: pb.SyncRequest
 	(*SyncyReply)(nil),          // 27: pb.SyncyReply
-	nil,                         // 28: pb.StatsReply.DataEntry
-	(*Block)(nil),               // 29: pb.Block
+	(*RewindRequest)(nil),       // 28: pb.RewindRequest
+	(*RewindReply)(nil),         // 29: pb.RewindReply
+	nil,                         // 30: pb.StatsReply.DataEntry
+	(*Block)(nil),               // 31: pb.Block
+	(*BlockInfo)(nil),           // 32: pb.BlockInfo
 }
 var file_pkg_pb_remote_proto_depIdxs = []int32{
-	28, // 0: pb.StatsReply.data:type_name -> pb.StatsReply.DataEntry
+	30, // 0: pb.StatsReply.data:type_name -> pb.StatsReply.DataEntry
 	22, // 1: pb.SnapshotRequest.open:type_name -> pb.SnapshotOpenRequest
 	2,  // 2: pb.SnapshotRequest.get:type_name -> pb.GetRequest
 	4,  // 3: pb.SnapshotRequest.has:type_name -> pb.HasRequest
@@ -1779,38 +1885,41 @@ var file_pkg_pb_remote_proto_depIdxs = []int32{
 	3,  // 6: pb.SnapshotReply.get:type_name -> pb.GetReply
 	5,  // 7: pb.SnapshotReply.has:type_name -> pb.HasReply
 	21, // 8: pb.SnapshotReply.close:type_name -> pb.CloseReply
-	29, // 9: pb.SyncyReply.data:type_name -> pb.Block
-	0,  // 10: pb.Remote.open:input_type -> pb.OpenRequest
-	2,  // 11: pb.Remote.get:input_type -> pb.GetRequest
-	4,  // 12: pb.Remote.has:input_type -> pb.HasRequest
-	14, // 13: pb.Remote.put:input_type -> pb.PutRequest
-	16, // 14: pb.Remote.del:input_type -> pb.DelRequest
-	6,  // 15: pb.Remote.stat:input_type -> pb.StatRequest
-	8,  // 16: pb.Remote.stats:input_type -> pb.StatsRequest
-	10, // 17: pb.Remote.compact:input_type -> pb.CompactRequest
-	12, // 18: pb.Remote.batch:input_type -> pb.BatchRequest
-	20, // 19: pb.Remote.close:input_type -> pb.CloseRequest
-	18, // 20: pb.Remote.iter:input_type -> pb.IterRequest
-	24, // 21: pb.Remote.snapshot:input_type -> pb.SnapshotRequest
-	26, // 22: pb.Remote.sync:input_type -> pb.SyncRequest
-	1,  // 23: pb.Remote.open:output_type -> pb.OpenReply
-	3,  // 24: pb.Remote.get:output_type -> pb.GetReply
-	5,  // 25: pb.Remote.has:output_type -> pb.HasReply
-	15, // 26: pb.Remote.put:output_type -> pb.PutReply
-	17, // 27: pb.Remote.del:output_type -> pb.DelReply
-	7,  // 28: pb.Remote.stat:output_type -> pb.StatReply
-	9,  // 29: pb.Remote.stats:output_type -> pb.StatsReply
-	11, // 30: pb.Remote.compact:output_type -> pb.CompactReply
-	13, // 31: pb.Remote.batch:output_type -> pb.BatchReply
-	21, // 32: pb.Remote.close:output_type -> pb.CloseReply
-	19, // 33: pb.Remote.iter:output_type -> pb.IterReply
-	25, // 34: pb.Remote.snapshot:output_type -> pb.SnapshotReply
-	27, // 35: pb.Remote.sync:output_type -> pb.SyncyReply
-	23, // [23:36] is the sub-list for method output_type
-	10, // [10:23] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	31, // 9: pb.SyncyReply.data:type_name -> pb.Block
+	32, // 10: pb.RewindReply.info:type_name -> pb.BlockInfo
+	0,  // 11: pb.Remote.open:input_type -> pb.OpenRequest
+	2,  // 12: pb.Remote.get:input_type -> pb.GetRequest
+	4,  // 13: pb.Remote.has:input_type -> pb.HasRequest
+	14, // 14: pb.Remote.put:input_type -> pb.PutRequest
+	16, // 15: pb.Remote.del:input_type -> pb.DelRequest
+	6,  // 16: pb.Remote.stat:input_type -> pb.StatRequest
+	8,  // 17: pb.Remote.stats:input_type -> pb.StatsRequest
+	10, // 18: pb.Remote.compact:input_type -> pb.CompactRequest
+	12, // 19: pb.Remote.batch:input_type -> pb.BatchRequest
+	20, // 20: pb.Remote.close:input_type -> pb.CloseRequest
+	18, // 21: pb.Remote.iter:input_type -> pb.IterRequest
+	24, // 22: pb.Remote.snapshot:input_type -> pb.SnapshotRequest
+	26, // 23: pb.Remote.sync:input_type -> pb.SyncRequest
+	28, // 24: pb.Remote.rewind:input_type -> pb.RewindRequest
+	1,  // 25: pb.Remote.open:output_type -> pb.OpenReply
+	3,  // 26: pb.Remote.get:output_type -> pb.GetReply
+	5,  // 27: pb.Remote.has:output_type -> pb.HasReply
+	15, // 28: pb.Remote.put:output_type -> pb.PutReply
+	17, // 29: pb.Remote.del:output_type -> pb.DelReply
+	7,  // 30: pb.Remote.stat:output_type -> pb.StatReply
+	9,  // 31: pb.Remote.stats:output_type -> pb.StatsReply
+	11, // 32: pb.Remote.compact:output_type -> pb.CompactReply
+	13, // 33: pb.Remote.batch:output_type -> pb.BatchReply
+	21, // 34: pb.Remote.close:output_type -> pb.CloseReply
+	19, // 35: pb.Remote.iter:output_type -> pb.IterReply
+	25, // 36: pb.Remote.snapshot:output_type -> pb.SnapshotReply
+	27, // 37: pb.Remote.sync:output_type -> pb.SyncyReply
+	29, // 38: pb.Remote.rewind:output_type -> pb.RewindReply
+	25, // [25:39] is the sub-list for method output_type
+	11, // [11:25] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_pkg_pb_remote_proto_init() }
@@ -2156,6 +2265,30 @@ func file_pkg_pb_remote_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_pb_remote_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RewindRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_pb_remote_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RewindReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_pkg_pb_remote_proto_msgTypes[24].OneofWrappers = []interface{}{
 		(*SnapshotRequest_Open)(nil),
@@ -2175,7 +2308,7 @@ func file_pkg_pb_remote_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_pb_remote_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   29,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -35,6 +35,7 @@ type RemoteClient interface {
 	Iter(ctx context.Context, in *IterRequest, opts ...grpc.CallOption) (Remote_IterClient, error)
 	Snapshot(ctx context.Context, opts ...grpc.CallOption) (Remote_SnapshotClient, error)
 	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (Remote_SyncClient, error)
+	Rewind(ctx context.Context, in *RewindRequest, opts ...grpc.CallOption) (*RewindReply, error)
 }
 
 type remoteClient struct {
@@ -230,6 +231,15 @@ func (x *remoteSyncClient) Recv() (*SyncyReply, error) {
 	return m, nil
 }
 
+func (c *remoteClient) Rewind(ctx context.Context, in *RewindRequest, opts ...grpc.CallOption) (*RewindReply, error) {
+	out := new(RewindReply)
+	err := c.cc.Invoke(ctx, "/pb.Remote/rewind", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RemoteServer is the server API for Remote service.
 // All implementations must embed UnimplementedRemoteServer
 // for forward compatibility
@@ -247,6 +257,7 @@ type RemoteServer interface {
 	Iter(*IterRequest, Remote_IterServer) error
 	Snapshot(Remote_SnapshotServer) error
 	Sync(*SyncRequest, Remote_SyncServer) error
+	Rewind(context.Context, *RewindRequest) (*RewindReply, error)
 	mustEmbedUnimplementedRemoteServer()
 }
 
@@ -293,6 +304,9 @@ func (UnimplementedRemoteServer) Snapshot(Remote_SnapshotServer) error {
 func (UnimplementedRemoteServer) Sync(*SyncRequest, Remote_SyncServer) error {
 	return status.Errorf(codes.Unimplemented, "method Sync not implemented")
 }
+func (UnimplementedRemoteServer) Rewind(context.Context, *RewindRequest) (*RewindReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rewind not implemented")
+}
 func (UnimplementedRemoteServer) mustEmbedUnimplementedRemoteServer() {}
 
 // UnsafeRemoteServer may be embedded to opt out of forward compatibility for this service.
@@ -554,6 +568,24 @@ func (x *remoteSyncServer) Send(m *SyncyReply) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Remote_Rewind_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RewindRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemoteServer).Rewind(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Remote/rewind",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemoteServer).Rewind(ctx, req.(*RewindRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Remote_ServiceDesc is the grpc.ServiceDesc for Remote service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -601,6 +633,10 @@ var Remote_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "close",
 			Handler:    _Remote_Close_Handler,
 		},
+		{
+			MethodName: "rewind",
+			Handler:    _Remote_Rewind_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -28,6 +28,8 @@ type S3ProxyClient interface {
 	PutFile(ctx context.Context, in *PutFileRequest, opts ...grpc.CallOption) (*PutFileReply, error)
 	ListHeaderStartAt(ctx context.Context, in *ListHeaderStartAtRequest, opts ...grpc.CallOption) (*ListHeaderStartAtReply, error)
 	RemoveFiles(ctx context.Context, in *RemoveFilesRequest, opts ...grpc.CallOption) (*RemoveFilesReply, error)
+	ListHeaders(ctx context.Context, in *ListHeadersRequest, opts ...grpc.CallOption) (S3Proxy_ListHeadersClient, error)
+	WatchBlocks(ctx context.Context, in *WatchBlocksRequest, opts ...grpc.CallOption) (S3Proxy_WatchBlocksClient, error)
 }
 
 type s3ProxyClient struct {
@@ -140,6 +142,70 @@ func (c *s3ProxyClient) RemoveFiles(ctx context.Context, in *RemoveFilesRequest,
 	return out, nil
 }
 
+func (c *s3ProxyClient) ListHeaders(ctx context.Context, in *ListHeadersRequest, opts ...grpc.CallOption) (S3Proxy_ListHeadersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &S3Proxy_ServiceDesc.Streams[2], "/pb.S3Proxy/ListHeaders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &s3ProxyListHeadersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type S3Proxy_ListHeadersClient interface {
+	Recv() (*ListHeadersReply, error)
+	grpc.ClientStream
+}
+
+type s3ProxyListHeadersClient struct {
+	grpc.ClientStream
+}
+
+func (x *s3ProxyListHeadersClient) Recv() (*ListHeadersReply, error) {
+	m := new(ListHeadersReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *s3ProxyClient) WatchBlocks(ctx context.Context, in *WatchBlocksRequest, opts ...grpc.CallOption) (S3Proxy_WatchBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &S3Proxy_ServiceDesc.Streams[3], "/pb.S3Proxy/WatchBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &s3ProxyWatchBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type S3Proxy_WatchBlocksClient interface {
+	Recv() (*WatchBlocksReply, error)
+	grpc.ClientStream
+}
+
+type s3ProxyWatchBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *s3ProxyWatchBlocksClient) Recv() (*WatchBlocksReply, error) {
+	m := new(WatchBlocksReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // S3ProxyServer is the server API for S3Proxy service.
 // All implementations must embed UnimplementedS3ProxyServer
 // for forward compatibility
@@ -150,6 +216,8 @@ type S3ProxyServer interface {
 	PutFile(context.Context, *PutFileRequest) (*PutFileReply, error)
 	ListHeaderStartAt(context.Context, *ListHeaderStartAtRequest) (*ListHeaderStartAtReply, error)
 	RemoveFiles(context.Context, *RemoveFilesRequest) (*RemoveFilesReply, error)
+	ListHeaders(*ListHeadersRequest, S3Proxy_ListHeadersServer) error
+	WatchBlocks(*WatchBlocksRequest, S3Proxy_WatchBlocksServer) error
 	mustEmbedUnimplementedS3ProxyServer()
 }
 
@@ -175,6 +243,12 @@ func (UnimplementedS3ProxyServer) ListHeaderStartAt(context.Context, *ListHeader
 func (UnimplementedS3ProxyServer) RemoveFiles(context.Context, *RemoveFilesRequest) (*RemoveFilesReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveFiles not implemented")
 }
+func (UnimplementedS3ProxyServer) ListHeaders(*ListHeadersRequest, S3Proxy_ListHeadersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListHeaders not implemented")
+}
+func (UnimplementedS3ProxyServer) WatchBlocks(*WatchBlocksRequest, S3Proxy_WatchBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBlocks not implemented")
+}
 func (UnimplementedS3ProxyServer) mustEmbedUnimplementedS3ProxyServer() {}
 
 // UnsafeS3ProxyServer may be embedded to opt out of forward compatibility for this service.
@@ -307,6 +381,48 @@ func _S3Proxy_RemoveFiles_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _S3Proxy_ListHeaders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListHeadersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(S3ProxyServer).ListHeaders(m, &s3ProxyListHeadersServer{stream})
+}
+
+type S3Proxy_ListHeadersServer interface {
+	Send(*ListHeadersReply) error
+	grpc.ServerStream
+}
+
+type s3ProxyListHeadersServer struct {
+	grpc.ServerStream
+}
+
+func (x *s3ProxyListHeadersServer) Send(m *ListHeadersReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _S3Proxy_WatchBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(S3ProxyServer).WatchBlocks(m, &s3ProxyWatchBlocksServer{stream})
+}
+
+type S3Proxy_WatchBlocksServer interface {
+	Send(*WatchBlocksReply) error
+	grpc.ServerStream
+}
+
+type s3ProxyWatchBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *s3ProxyWatchBlocksServer) Send(m *WatchBlocksReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // S3Proxy_ServiceDesc is the grpc.ServiceDesc for S3Proxy service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -342,6 +458,16 @@ var S3Proxy_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _S3Proxy_PutBlock_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "ListHeaders",
+			Handler:       _S3Proxy_ListHeaders_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchBlocks",
+			Handler:       _S3Proxy_WatchBlocks_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pkg/pb/store.proto",
 }
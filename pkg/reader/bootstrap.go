@@ -0,0 +1,127 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/syndtr/goleveldb/leveldb"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// Bootstrap installs the latest snapshot of the chain at the DB paths of
+// config.DBInfoPath, DBs are matched by id and their paths must not exist or
+// be empty. A reader started afterwards resumes from the offset of the
+// snapshot. DBs which already hold a block are kept, so a restarted reader
+// resumes from them instead.
+func Bootstrap(ctx context.Context, config *utils.Config) (*pb.BlockInfo, error) {
+	dbInfos, err := utils.OpenAndReadDbInfo(config.DBInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	// the DBs of an interrupted bootstrap are moved in place or discarded.
+	err = db.RecoverInstall(dbInfos)
+	if err != nil {
+		return nil, err
+	}
+	last, err := installedBlock(dbInfos, config.DBCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		utils.Logger().Info("Bootstrap skipped, dbs already hold a block", zap.Int64("BlockNum", last.BlockNum),
+			zap.String("BlockHash", last.BlockHash))
+		return last, nil
+	}
+	client, err := s3.NewClient(config.S3ProxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	// GetFile returns no bytes for a missing object.
+	manifestKey, err := client.GetFile(ctx, utils.SnapshotLatestKey(config.Env, config.ChainId, config.Role))
+	if err == nil && len(manifestKey) == 0 {
+		err = utils.ErrNoSnapshot
+	}
+	if err != nil {
+		utils.Logger().Error("Bootstrap latest snapshot not found", zap.Error(err))
+		return nil, err
+	}
+	buf, err := client.GetFile(ctx, string(manifestKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		utils.Logger().Error("Bootstrap snapshot manifest not found", zap.String("manifest", string(manifestKey)))
+		return nil, utils.ErrNoSnapshot
+	}
+	manifest := &pb.Snapshot{}
+	if err := proto.Unmarshal(buf, manifest); err != nil {
+		return nil, err
+	}
+	utils.Logger().Info("Bootstrap", zap.String("manifest", string(manifestKey)), zap.Any("info", manifest.Info))
+
+	layers := make(map[int32][]*pb.SnapshotDB)
+	for _, snapshotDB := range manifest.Dbs {
+		layers[snapshotDB.DbInfo.Id] = []*pb.SnapshotDB{snapshotDB}
+	}
+	err = db.Install(dbInfos, layers, config.DBCacheSize, func(chunk *pb.SnapshotChunk) ([]byte, error) {
+		return client.GetCompressedFile(ctx, chunk)
+	})
+	if err != nil {
+		utils.Logger().Error("Bootstrap install error", zap.Error(err))
+		return nil, err
+	}
+	utils.Logger().Info("Bootstrap success", zap.Any("info", manifest.Info))
+	return manifest.Info, nil
+}
+
+// installedBlock returns the last block applied to the DBs of dbInfos, nil if
+// all their paths are empty. The DBs must be all empty or all populated with
+// a block.
+func installedBlock(dbInfos *pb.DBInfoList, cacheSize int) (*pb.BlockInfo, error) {
+	populated := 0
+	for _, dbInfo := range dbInfos.DbInfos {
+		entries, err := os.ReadDir(dbInfo.DbPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(entries) > 0 {
+			populated++
+		}
+	}
+	if populated == 0 {
+		return nil, nil
+	}
+	if populated < len(dbInfos.DbInfos) {
+		return nil, fmt.Errorf("db paths partially populated, %d of %d are not empty", populated, len(dbInfos.DbInfos))
+	}
+	for _, dbInfo := range dbInfos.DbInfos {
+		if !dbInfo.IsMeta {
+			continue
+		}
+		ldb, err := db.NewLDB(dbInfo.DbPath, cacheSize)
+		if err != nil {
+			return nil, err
+		}
+		defer ldb.Close()
+		buf, err := ldb.Get([]byte(db.LastBlockInfo))
+		if err != nil && err != leveldb.ErrNotFound {
+			return nil, err
+		}
+		if len(buf) == 0 {
+			break
+		}
+		last := &pb.BlockInfo{}
+		if err := proto.Unmarshal(buf, last); err != nil {
+			return nil, err
+		}
+		return last, nil
+	}
+	return nil, errors.New("db paths are not empty but hold no block")
+}
@@ -0,0 +1,112 @@
+package reader
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	writer "github.com/DeBankDeFi/nodex/pkg/writer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBootstrap(t *testing.T) {
+	go s3.ListenAndServe("0.0.0.0:8769", 32, s3.NewMemStore())
+	client, err := s3.NewClient("0.0.0.0:8769")
+	require.NoErrorf(t, err, "NewClient error")
+	client.SetCompression(pb.BlockInfo_ZSTD)
+
+	src := db.NewDBPool()
+	for id := int32(0); id < 2; id++ {
+		ldb, err := db.NewLDB(t.TempDir(), 16)
+		require.NoErrorf(t, err, "NewLDB error")
+		require.NoError(t, ldb.Put([]byte("key"), []byte{byte(id)}))
+		src.Register(id, "src", "leveldb", ldb, id == 0)
+	}
+	defer src.Close()
+	require.NoError(t, src.WriteBlockInfo(&pb.BlockInfo{ChainId: "256", BlockNum: 5, BlockHash: "5", MsgOffset: 9}))
+	ps, err := src.Snapshot()
+	require.NoErrorf(t, err, "Snapshot error")
+	metaDB, err := src.GetDB(0)
+	require.NoError(t, err)
+	require.NoError(t, metaDB.Put([]byte("after"), []byte("snapshot")))
+
+	require.Eventually(t, func() bool {
+		_, err = writer.ExportSnapshot(context.Background(), client, "test", "256", "master", ps)
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	ps.Release()
+
+	dir := t.TempDir()
+	dbInfos := &pb.DBInfoList{}
+	for id := int32(0); id < 2; id++ {
+		dbInfos.DbInfos = append(dbInfos.DbInfos, &pb.DBInfo{
+			Id:     id,
+			DbType: "leveldb",
+			DbPath: filepath.Join(dir, string(rune('a'+id))),
+			IsMeta: id == 0,
+		})
+	}
+	buf, err := json.Marshal(dbInfos)
+	require.NoError(t, err)
+	config := &utils.Config{
+		S3ProxyAddr: "0.0.0.0:8769",
+		Env:         "test",
+		ChainId:     "256",
+		Role:        "master",
+		DBInfoPath:  filepath.Join(dir, "dbinfo.json"),
+		DBCacheSize: 16,
+	}
+	require.NoError(t, os.WriteFile(config.DBInfoPath, buf, 0644))
+
+	// a chain without snapshot can not be bootstrapped.
+	config.ChainId = "257"
+	_, err = Bootstrap(context.Background(), config)
+	require.ErrorIs(t, err, utils.ErrNoSnapshot)
+	config.ChainId = "256"
+
+	info, err := Bootstrap(context.Background(), config)
+	require.NoErrorf(t, err, "Bootstrap error")
+	require.Equal(t, int64(9), info.MsgOffset)
+
+	dst := db.NewDBPool()
+	for _, dbInfo := range dbInfos.DbInfos {
+		require.NoError(t, dst.Open(dbInfo, 16))
+	}
+	metaDB, err = dst.GetDB(0)
+	require.NoError(t, err)
+	buf, err = metaDB.Get([]byte(db.LastBlockInfo))
+	require.NoError(t, err)
+	last := &pb.BlockInfo{}
+	require.NoError(t, proto.Unmarshal(buf, last))
+	require.Equal(t, int64(5), last.BlockNum)
+	require.Equal(t, int64(9), last.MsgOffset)
+	for id := int32(0); id < 2; id++ {
+		ldb, err := dst.GetDB(id)
+		require.NoError(t, err)
+		val, err := ldb.Get([]byte("key"))
+		require.NoError(t, err)
+		require.Equal(t, []byte{byte(id)}, val)
+	}
+	has, err := metaDB.Has([]byte("after"))
+	require.NoError(t, err)
+	require.False(t, has)
+	dst.Close()
+
+	// a restart keeps the installed dbs.
+	info, err = Bootstrap(context.Background(), config)
+	require.NoErrorf(t, err, "Bootstrap error")
+	require.Equal(t, int64(5), info.BlockNum)
+
+	// a partially populated set of dbs is refused.
+	require.NoError(t, os.RemoveAll(dbInfos.DbInfos[1].DbPath))
+	_, err = Bootstrap(context.Background(), config)
+	require.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package reader
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// checkpoint snapshots the DBs every CheckpointInterval seconds unless the
+// previous checkpoint is still exported, it must be called from fetchRun so
+// no block is applied meanwhile.
+func (r *Reader) checkpoint() {
+	if r.checkpointer == nil || time.Since(r.checkpointAt) < time.Duration(r.config.CheckpointInterval)*time.Second {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&r.checkpointing, 0, 1) {
+		return
+	}
+	r.checkpointAt = time.Now()
+	prepared, err := r.checkpointer.Prepare()
+	if err != nil {
+		atomic.StoreInt32(&r.checkpointing, 0)
+		utils.Logger().Error("checkpoint Prepare error", zap.Error(err))
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&r.checkpointing, 0)
+		startTime := time.Now()
+		checkpoint, err := r.checkpointer.Export(r.rootCtx, prepared)
+		if err != nil {
+			utils.Logger().Error("checkpoint Export error", zap.Error(err))
+			return
+		}
+		utils.Logger().Info("checkpoint Export success", zap.String("key", checkpoint.Key),
+			zap.String("parent", checkpoint.Parent), zap.Int64("BlockNum", checkpoint.Info.GetBlockNum()),
+			zap.Duration("duration", time.Since(startTime)))
+	}()
+}
@@ -0,0 +1,118 @@
+package reader
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	DiscoveryKafka = "kafka"
+	DiscoveryS3    = "s3"
+	DiscoveryAuto  = "auto"
+
+	// listBatch is the number of headers above the reorg window listed at once.
+	listBatch = 128
+	// listInterval is the min interval of listings which found no new header.
+	listInterval = time.Second
+	// discoveryRetry is the interval the HeaderBus is retried at in auto mode.
+	discoveryRetry = 30 * time.Second
+)
+
+func checkDiscovery(discovery string) error {
+	switch discovery {
+	case "", DiscoveryKafka, DiscoveryS3, DiscoveryAuto:
+		return nil
+	default:
+		return fmt.Errorf("unknown discovery: %s", discovery)
+	}
+}
+
+// discover returns the BlockInfos after the last applied one, listed is true
+// if they were listed from s3. In auto mode the reader lists s3 while the
+// HeaderBus fails and goes back to it once it syncs again.
+func (r *Reader) discover() (infos []*pb.BlockInfo, listed bool, err error) {
+	switch {
+	case r.config.Discovery == DiscoveryS3:
+		infos, err = r.listInfos()
+		return infos, true, err
+	case r.config.Discovery == DiscoveryAuto && r.listing:
+		if time.Since(r.listingSince) < discoveryRetry {
+			infos, err = r.listInfos()
+			return infos, true, err
+		}
+		err = r.bus.Sync()
+		if err != nil {
+			utils.Logger().Warn("header bus still unavailable", zap.Error(err))
+			r.listingSince = time.Now()
+			infos, err = r.listInfos()
+			return infos, true, err
+		}
+		utils.Logger().Info("header bus available, switch discovery to header bus",
+			zap.Int64("readerLastOffset", r.bus.LastReaderOffset()))
+		r.listing = false
+	}
+	infos, err = r.bus.Fetch(r.rootCtx)
+	if err != nil && r.config.Discovery == DiscoveryAuto {
+		utils.Logger().Warn("header bus fetch error, switch discovery to s3 listing", zap.Error(err))
+		r.listing = true
+		r.listingSince = time.Now()
+		infos, err = r.listInfos()
+		return infos, true, err
+	}
+	return infos, false, err
+}
+
+// listInfos lists the headers written after the last applied one from the
+// heights of the reorg window and above. They are ordered by MsgOffset and
+// cut at the first missing offset, so blocks are applied in the same order as
+// from the HeaderBus.
+func (r *Reader) listInfos() ([]*pb.BlockInfo, error) {
+	if time.Since(r.listedAt) < listInterval {
+		return nil, nil
+	}
+	start := r.lastBlockHeader.BlockNum - int64(r.config.ReorgDeep)
+	if start < 0 {
+		start = 0
+	}
+	listed, err := r.s3.ListHeaderStartAt(r.rootCtx, r.config.ChainId, r.config.Env, r.config.Role,
+		start, int64(r.config.ReorgDeep)+listBatch, r.bus.LastReaderOffset())
+	if err != nil {
+		utils.Logger().Error("ListHeaderStartAt error", zap.Error(err))
+		return nil, err
+	}
+	sort.Slice(listed, func(i, j int) bool {
+		return listed[i].MsgOffset < listed[j].MsgOffset
+	})
+	var infos []*pb.BlockInfo
+	next := r.bus.LastReaderOffset() + 1
+	for _, info := range listed {
+		if info.MsgOffset < next {
+			continue
+		}
+		if info.MsgOffset != next {
+			utils.Logger().Warn("listInfos missing header", zap.Int64("MsgOffset", next), zap.Int64("BlockNum", info.BlockNum))
+			break
+		}
+		infos = append(infos, info)
+		next++
+	}
+	if len(infos) == 0 {
+		r.listedAt = time.Now()
+	}
+	return infos, nil
+}
+
+// listedInfo returns the full BlockInfo of a listed block, a listed info only
+// has the fields kept in the key of its header.
+func listedInfo(info *pb.BlockInfo, headerFile *pb.Block) *pb.BlockInfo {
+	if headerFile.Info == nil || headerFile.Info.BlockHash != info.BlockHash || headerFile.Info.MsgOffset != info.MsgOffset {
+		return info
+	}
+	return proto.Clone(headerFile.Info).(*pb.BlockInfo)
+}
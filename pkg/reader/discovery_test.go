@@ -0,0 +1,71 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/kafka"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListInfos(t *testing.T) {
+	go s3.ListenAndServe("0.0.0.0:8768", 32, s3.NewMemStore())
+	client, err := s3.NewClient("0.0.0.0:8768")
+	require.NoErrorf(t, err, "NewClient error")
+
+	// block 3 is reorged at offset 5 and offset 6 is not uploaded yet.
+	headers := []struct {
+		blockNum  int64
+		msgOffset int64
+	}{{0, 0}, {1, 1}, {2, 2}, {3, 3}, {4, 4}, {3, 5}, {5, 7}}
+	for _, header := range headers {
+		block := &pb.Block{
+			Info: &pb.BlockInfo{
+				ChainId:   "256",
+				Env:       "test",
+				Role:      "master",
+				BlockNum:  header.blockNum,
+				BlockHash: fmt.Sprintf("%d-%d", header.blockNum, header.msgOffset),
+				BlockRoot: "root",
+				MsgOffset: header.msgOffset,
+				BlockType: pb.BlockInfo_HEADER,
+			},
+		}
+		require.Eventually(t, func() bool {
+			return client.PutBlock(context.Background(), block) == nil
+		}, 5*time.Second, 100*time.Millisecond)
+	}
+
+	bus, err := kafka.NewMemoryBus("discovery", 0, 1)
+	require.NoErrorf(t, err, "NewMemoryBus error")
+	r := &Reader{
+		config:          &utils.Config{ChainId: "256", Env: "test", Role: "master", ReorgDeep: 2},
+		s3:              client,
+		bus:             bus,
+		lastBlockHeader: &pb.BlockInfo{BlockNum: 1, MsgOffset: 1},
+		rootCtx:         context.Background(),
+	}
+	infos, err := r.listInfos()
+	require.NoErrorf(t, err, "listInfos error")
+	var offsets []int64
+	for _, info := range infos {
+		offsets = append(offsets, info.MsgOffset)
+	}
+	require.Equal(t, []int64{2, 3, 4, 5}, offsets)
+	require.Equal(t, int64(3), infos[3].BlockNum)
+
+	header, err := client.GetBlock(context.Background(), infos[0], false)
+	require.NoErrorf(t, err, "GetBlock error")
+	require.Equal(t, "root", listedInfo(infos[0], header).BlockRoot)
+
+	bus.ResetLastReaderOffset(7)
+	infos, err = r.listInfos()
+	require.NoErrorf(t, err, "listInfos error")
+	require.Empty(t, infos)
+	require.False(t, r.listedAt.IsZero())
+}
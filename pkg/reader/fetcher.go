@@ -1,94 +1,279 @@
 package reader
 
 import (
+	"context"
+	"crypto/ed25519"
 	"runtime"
 	"time"
 
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
 	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
 	"github.com/DeBankDeFi/nodex/pkg/utils"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
 func (r *Reader) fetchAndCommit() error {
-	infos, err := r.kafka.Fetch(r.rootCtx)
+	infos, listed, err := r.discover()
 	if err != nil {
-		utils.Logger().Error("ListHeaderStartAt error", zap.Error(err))
+		utils.Logger().Error("discover error", zap.Error(err))
 		return err
 	}
+	ctx, cancel := context.WithCancel(r.rootCtx)
+	blocks := r.prefetcher.run(ctx, infos)
+	defer func() {
+		cancel()
+		r.prefetcher.drain(blocks)
+	}()
 	for i, info := range infos {
 		if i%100 == 0 {
 			runtime.GC()
 		}
 		utils.Logger().Info("new header", zap.Any("BlockNum", info.BlockNum), zap.Any("MsgOffset", info.MsgOffset))
-		headerFile, err := r.s3.GetBlock(r.rootCtx, info, true)
+		block, ok := <-blocks
+		if !ok {
+			return ctx.Err()
+		}
+		<-block.done
+		r.prefetcher.release()
+		if block.err != nil {
+			return block.err
+		}
+		if listed {
+			info = listedInfo(info, block.header)
+		}
+		err = verifyBlock(r.metrics, r.trustedKeys, info, block.header, block.data)
 		if err != nil {
-			utils.Logger().Error("GetHeaderFile error", zap.Error(err), zap.Any("info", info))
+			utils.Logger().Error("verifyBlock error", zap.Error(err))
 			return err
 		}
-		info.BlockType = pb.BlockInfo_DATA
-		blockFile, err := r.s3.GetBlock(r.rootCtx, info, true)
+		applied, err := r.checkReorg(info)
 		if err != nil {
-			utils.Logger().Error("GetBlockFile error", zap.Error(err), zap.Any("hash", headerFile.Info.BlockHash))
 			return err
 		}
-		if blockFile != nil {
-			err = r.dbPool.WriteBatchItems(blockFile.BatchItems)
-			if err != nil {
-				utils.Logger().Error("WriteBatchItems error", zap.Error(err))
-				return err
-			}
+		if applied {
+			utils.Logger().Info("skip applied block", zap.Int64("BlockNum", info.BlockNum), zap.String("BlockHash", info.BlockHash))
+			r.bus.IncrementLastReaderOffset()
+			continue
 		}
-		err = r.dbPool.WriteBatchItems(headerFile.BatchItems)
+		undo, err := r.undoLog(info, block.header, block.data)
 		if err != nil {
-			utils.Logger().Error("WriteBatchItems error", zap.Error(err))
+			utils.Logger().Error("BuildUndoLog error", zap.Error(err))
 			return err
 		}
-		err = r.dbPool.WriteBlockInfo(info)
+		err = commitBlock(r.dbPool, info, block.header, block.data, undo, r.topicOffset(r.bus.LastReaderOffset()+1))
 		if err != nil {
-			utils.Logger().Error("WriteBlockInfo error", zap.Error(err))
 			return err
 		}
+		if window := r.undoWindow(); window > 0 {
+			err = r.dbPool.PruneUndoLogs(info.BlockNum - int64(window) + 1)
+			if err != nil {
+				utils.Logger().Error("PruneUndoLogs error", zap.Error(err))
+			}
+		}
+		headerFile := block.header
 		headerFile.BatchItems = nil
 		r.broker.publish(headerFile)
 		r.lastBlockHeader = info
-		if r.kafka.LastReaderOffset()+1 != r.lastBlockHeader.MsgOffset {
-			utils.Logger().Error("LastReaderOffset error", zap.Any("kafka", r.kafka.LastReaderOffset()), zap.Any("block", r.lastBlockHeader.MsgOffset))
+		if r.bus.LastReaderOffset()+1 != r.lastBlockHeader.MsgOffset {
+			utils.Logger().Error("LastReaderOffset error", zap.Any("kafka", r.bus.LastReaderOffset()), zap.Any("block", r.lastBlockHeader.MsgOffset))
 		}
-		r.kafka.IncrementLastReaderOffset()
+		r.bus.IncrementLastReaderOffset()
 		utils.Logger().Info("Apply Block success", zap.Any("blockInfo", r.lastBlockHeader.String()))
 	}
 	return nil
 }
 
-func (r *Reader) reset(role string) error {
-	topic := utils.Topic(r.config.Env, r.config.ChainId, role)
-	r.kafka.ResetTopic(topic)
-	infos, err := r.s3.ListHeaderStartAt(r.rootCtx, r.config.ChainId, r.config.Env, role,
-		r.lastBlockHeader.BlockNum-1, 5, -1)
+// fetchBlock downloads the header and data blocks of info, info is not modified
+// so it is safe to call concurrently with the application of earlier blocks.
+func fetchBlock(ctx context.Context, s3 *s3.Client, info *pb.BlockInfo) (headerFile *pb.Block, blockFile *pb.Block, err error) {
+	headerFile, err = s3.GetBlock(ctx, info, true)
+	if err != nil {
+		utils.Logger().Error("GetHeaderFile error", zap.Error(err), zap.Any("info", info))
+		return nil, nil, err
+	}
+	dataInfo := proto.Clone(info).(*pb.BlockInfo)
+	dataInfo.BlockType = pb.BlockInfo_DATA
+	if len(dataInfo.DataChecksum) == 0 && headerFile.Info != nil {
+		dataInfo.DataChecksum = headerFile.Info.DataChecksum
+	}
+	blockFile, err = s3.GetBlock(ctx, dataInfo, true)
+	if err != nil {
+		utils.Logger().Error("GetBlockFile error", zap.Error(err), zap.Any("hash", headerFile.Info.BlockHash))
+		return nil, nil, err
+	}
+	return headerFile, blockFile, nil
+}
+
+// verifyBlock checks the block of info was signed by one of the trusted
+// writer keys, every block is trusted if there is none.
+func verifyBlock(m *metrics.ReaderMetrics, keys []ed25519.PublicKey, info *pb.BlockInfo, headerFile *pb.Block, blockFile *pb.Block) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var data []*pb.Data
+	if blockFile != nil {
+		data = blockFile.BatchItems
+	}
+	err := utils.VerifyBlock(keys, info, utils.BlockDigest(utils.ItemsDigest(data), utils.ItemsDigest(headerFile.BatchItems)))
+	if err != nil {
+		m.IncreaseBadSignatures(info.ChainId)
+	}
+	return err
+}
+
+// blockItems returns the batch items of a block in the order they are applied.
+func blockItems(headerFile *pb.Block, blockFile *pb.Block) (items []*pb.Data) {
+	if blockFile != nil {
+		items = append(items, blockFile.BatchItems...)
+	}
+	return append(items, headerFile.BatchItems...)
+}
+
+// commitBlock writes the data and header batch items of the block to DBs and
+// commits its info. An apply journal and the UndoLog of the block, if any, are
+// kept in the meta DB before the items are written, replaying the batches is
+// idempotent so an interrupted apply can be redone by recoverApply.
+func commitBlock(dbPool *db.DBPool, info *pb.BlockInfo, headerFile *pb.Block, blockFile *pb.Block, undo *pb.UndoLog,
+	offsets ...db.TopicOffset) (err error) {
+	err = dbPool.WriteApplyJournal(info, undo)
 	if err != nil {
-		utils.Logger().Error("ListHeaderStartAt error", zap.Error(err))
+		utils.Logger().Error("WriteApplyJournal error", zap.Error(err))
 		return err
 	}
-	for _, info := range infos {
-		if info.BlockHash == r.lastBlockHeader.BlockHash {
-			r.lastBlockHeader = info
-			r.config.Role = role
-			r.kafka.ResetLastReaderOffset(info.MsgOffset)
-			return nil
+	if blockFile != nil {
+		err = dbPool.WriteBatchItems(blockFile.BatchItems)
+		if err != nil {
+			utils.Logger().Error("WriteBatchItems error", zap.Error(err))
+			return err
 		}
 	}
-	infos, err = r.s3.ListHeaderStartAt(r.rootCtx, r.config.ChainId, r.config.Env, role,
-		r.lastBlockHeader.BlockNum-128, 5, -1)
+	err = dbPool.WriteBatchItems(headerFile.BatchItems)
 	if err != nil {
-		utils.Logger().Error("ListHeaderStartAt error", zap.Error(err))
+		utils.Logger().Error("WriteBatchItems error", zap.Error(err))
+		return err
+	}
+	info.BlockType = pb.BlockInfo_DATA
+	err = dbPool.WriteBlockInfo(info, offsets...)
+	if err != nil {
+		utils.Logger().Error("WriteBlockInfo error", zap.Error(err))
 		return err
 	}
-	r.lastBlockHeader = infos[0]
-	r.config.Role = role
-	r.kafka.ResetLastReaderOffset(r.lastBlockHeader.MsgOffset)
 	return nil
 }
 
+// recoverApply re-applies the block left in the apply journal by a crash.
+func recoverApply(ctx context.Context, s3 *s3.Client, dbPool *db.DBPool, m *metrics.ReaderMetrics, trustedKeys []ed25519.PublicKey) error {
+	info, err := dbPool.GetApplyJournal()
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return nil
+	}
+	utils.Logger().Info("recoverApply", zap.Int64("block_num", info.BlockNum), zap.String("block_hash", info.BlockHash),
+		zap.Int64("msg_offset", info.MsgOffset))
+	info.BlockType = pb.BlockInfo_HEADER
+	headerFile, blockFile, err := fetchBlock(ctx, s3, info)
+	if err != nil {
+		return err
+	}
+	err = verifyBlock(m, trustedKeys, info, headerFile, blockFile)
+	if err != nil {
+		return err
+	}
+	// the DBs may be partially written, keep the UndoLog recorded before.
+	undo, err := dbPool.GetUndoLog(info.BlockNum)
+	if err != nil {
+		return err
+	}
+	if undo != nil && (undo.Info.BlockHash != info.BlockHash || undo.Info.MsgOffset != info.MsgOffset) {
+		undo = nil
+	}
+	return commitBlock(dbPool, info, headerFile, blockFile, undo)
+}
+
+// topicOffset returns the TopicOffset of the current topic partition.
+func (r *Reader) topicOffset(offset int64) db.TopicOffset {
+	return db.TopicOffset{
+		Topic:     r.bus.Topic(),
+		Partition: r.bus.Partition(),
+		Offset:    offset,
+	}
+}
+
+func (r *Reader) reset(role string) error {
+	topic := utils.Topic(r.config.Env, r.config.ChainId, role)
+	r.bus.ResetTopic(topic)
+	var found *pb.BlockInfo
+	err := r.s3.ListHeaders(r.rootCtx, r.config.ChainId, r.config.Env, role,
+		r.lastBlockHeader.BlockNum-1, r.lastBlockHeader.BlockNum+2, -1, func(info *pb.BlockInfo) bool {
+			if info.BlockHash == r.lastBlockHeader.BlockHash {
+				found = info
+				return false
+			}
+			return true
+		})
+	if err != nil {
+		utils.Logger().Error("ListHeaders error", zap.Error(err))
+		return err
+	}
+	if found != nil {
+		r.lastBlockHeader = found
+		r.config.Role = role
+		r.bus.ResetLastReaderOffset(found.MsgOffset)
+		return nil
+	}
+	// the chain of role forked from the applied one, the applied blocks above
+	// their common ancestor are rolled back so its blocks are applied on top.
+	last := r.lastBlockHeader
+	start := last.BlockNum - int64(r.config.ReorgDeep)
+	if start < 0 {
+		start = 0
+	}
+	headers := make(map[int64]map[string]*pb.BlockInfo)
+	err = r.s3.ListHeaders(r.rootCtx, r.config.ChainId, r.config.Env, role,
+		start, last.BlockNum, -1, func(info *pb.BlockInfo) bool {
+			if headers[info.BlockNum] == nil {
+				headers[info.BlockNum] = make(map[string]*pb.BlockInfo)
+			}
+			headers[info.BlockNum][info.BlockHash] = info
+			return true
+		})
+	if err != nil {
+		utils.Logger().Error("ListHeaders error", zap.Error(err))
+		return err
+	}
+	for n := last.BlockNum - 1; n >= start; n-- {
+		undo, err := r.dbPool.GetUndoLog(n)
+		if err != nil {
+			return err
+		}
+		if undo == nil {
+			break
+		}
+		ancestor, ok := headers[n][undo.Info.BlockHash]
+		if !ok {
+			continue
+		}
+		utils.Logger().Warn("reset rollback", zap.String("role", role), zap.Int64("depth", last.BlockNum-n),
+			zap.Int64("BlockNum", n), zap.String("BlockHash", ancestor.BlockHash))
+		err = r.rollback(n)
+		if err != nil {
+			return err
+		}
+		r.lastBlockHeader = ancestor
+		r.config.Role = role
+		r.bus.ResetLastReaderOffset(ancestor.MsgOffset)
+		return nil
+	}
+	utils.Logger().Error("reset common ancestor not found", zap.String("role", role),
+		zap.Int64("lastBlockNum", last.BlockNum), zap.Int("reorgDeep", r.config.ReorgDeep))
+	return utils.ErrReorgTooDeep
+}
+
 func (r *Reader) fetchRun() {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	for {
@@ -98,6 +283,15 @@ func (r *Reader) fetchRun() {
 			if err != nil {
 				utils.Logger().Error("fetchAndCommit error", zap.Error(err))
 			}
+			r.commitGroupOffset()
+			r.checkpoint()
+		case req := <-r.rewindC:
+			req.info, req.err = r.rewind(req.blockNum)
+			if req.err != nil {
+				utils.Logger().Error("rewind error", zap.Error(req.err))
+			}
+			r.commitGroupOffset()
+			close(req.done)
 		case role := <-r.resetC:
 			utils.Logger().Info("reset", zap.Any("new role", role), zap.Any("old role", r.config.Role))
 			if role == r.config.Role || role == "" {
@@ -106,7 +300,10 @@ func (r *Reader) fetchRun() {
 			err := r.reset(role)
 			if err != nil {
 				utils.Logger().Error("reset error", zap.Error(err))
+			} else {
+				r.watch()
 			}
+			r.commitGroupOffset()
 		case <-r.rootCtx.Done():
 			r.stopdoneC <- struct{}{}
 			return
@@ -0,0 +1,60 @@
+package reader
+
+import (
+	"context"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/kafka"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// checkGroupOffset cross-checks offset, the last applied offset recorded in
+// the meta DB, with the offset committed to the consumer group. A group which
+// has not committed yet is accepted.
+func checkGroupOffset(ctx context.Context, bus kafka.HeaderBus, config *utils.Config, offset int64) error {
+	if config.KafkaGroupId == "" {
+		return nil
+	}
+	groupOffset, ok, err := bus.CommittedOffset(ctx, config.KafkaGroupId)
+	if err != nil {
+		utils.Logger().Error("CommittedOffset error", zap.Error(err), zap.String("group", config.KafkaGroupId))
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	diff := groupOffset - offset
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > int64(config.KafkaOffsetMaxDiff) {
+		utils.Logger().Error("meta db offset diverged from consumer group offset", zap.String("topic", bus.Topic()),
+			zap.Int("partition", bus.Partition()), zap.String("group", config.KafkaGroupId),
+			zap.Int64("dbOffset", offset), zap.Int64("groupOffset", groupOffset), zap.Int("maxDiff", config.KafkaOffsetMaxDiff))
+		return utils.ErrOffsetDiverged
+	}
+	return nil
+}
+
+// commitGroupOffset commits the last applied offset to the consumer group if
+// it changed. The meta DB stays the source of truth, a failed commit is only
+// logged and retried with the next one.
+func (r *Reader) commitGroupOffset() {
+	if r.config.KafkaGroupId == "" {
+		return
+	}
+	offset := r.bus.LastReaderOffset()
+	if offset == r.groupOffset {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.rootCtx, 5*time.Second)
+	defer cancel()
+	err := r.bus.CommitOffset(ctx, r.config.KafkaGroupId, offset)
+	if err != nil {
+		utils.Logger().Error("CommitOffset error", zap.Error(err), zap.String("group", r.config.KafkaGroupId),
+			zap.Int64("offset", offset))
+		return
+	}
+	r.groupOffset = offset
+}
@@ -0,0 +1,82 @@
+package reader
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+)
+
+// prefetchedBlock is the header and data blocks of a BlockInfo downloaded
+// ahead of its application, done is closed once the download finished.
+type prefetchedBlock struct {
+	header *pb.Block
+	data   *pb.Block
+	err    error
+	done   chan struct{}
+}
+
+// prefetcher downloads the blocks of up to depth BlockInfos concurrently while
+// handing them out in Kafka order.
+type prefetcher struct {
+	chain     string
+	s3        *s3.Client
+	slots     chan struct{}
+	occupancy int64
+	metrics   *metrics.ReaderMetrics
+}
+
+func newPrefetcher(chain string, s3Client *s3.Client, depth int) *prefetcher {
+	if depth < 1 {
+		depth = 1
+	}
+	return &prefetcher{
+		chain:   chain,
+		s3:      s3Client,
+		slots:   make(chan struct{}, depth),
+		metrics: metrics.NewReaderMetrics(),
+	}
+}
+
+// run starts downloading the blocks of infos, the returned channel yields one
+// prefetchedBlock per info in order and is closed when all were handed out
+// or ctx is done. A block holds a slot until release is called, so at most
+// depth blocks are downloaded but not yet applied.
+func (p *prefetcher) run(ctx context.Context, infos []*pb.BlockInfo) <-chan *prefetchedBlock {
+	blocks := make(chan *prefetchedBlock, len(infos))
+	go func() {
+		defer close(blocks)
+		for _, info := range infos {
+			select {
+			case p.slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			p.metrics.SetPrefetchOccupancy(p.chain, atomic.AddInt64(&p.occupancy, 1))
+			block := &prefetchedBlock{done: make(chan struct{})}
+			go func(info *pb.BlockInfo) {
+				defer close(block.done)
+				block.header, block.data, block.err = fetchBlock(ctx, p.s3, info)
+			}(info)
+			blocks <- block
+		}
+	}()
+	return blocks
+}
+
+// release frees the slot of a consumed block.
+func (p *prefetcher) release() {
+	p.metrics.SetPrefetchOccupancy(p.chain, atomic.AddInt64(&p.occupancy, -1))
+	<-p.slots
+}
+
+// drain waits for and releases the blocks left after the consumer stopped
+// early, ctx of run must be done.
+func (p *prefetcher) drain(blocks <-chan *prefetchedBlock) {
+	for block := range blocks {
+		<-block.done
+		p.release()
+	}
+}
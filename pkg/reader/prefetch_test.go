@@ -0,0 +1,64 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetcher(t *testing.T) {
+	go s3.ListenAndServe("0.0.0.0:8767", 32, s3.NewMemStore())
+	client, err := s3.NewClient("0.0.0.0:8767")
+	require.NoErrorf(t, err, "NewClient error")
+
+	var infos []*pb.BlockInfo
+	for i := 0; i < 20; i++ {
+		info := &pb.BlockInfo{
+			ChainId:   "256",
+			Env:       "test",
+			Role:      "master",
+			BlockNum:  int64(i),
+			BlockHash: fmt.Sprintf("%d", i),
+			MsgOffset: int64(i),
+		}
+		for _, blockType := range []pb.BlockInfo_BlockType{pb.BlockInfo_DATA, pb.BlockInfo_HEADER} {
+			info.BlockType = blockType
+			block := &pb.Block{
+				Info:       info,
+				BatchItems: []*pb.Data{{Id: 0, Data: []byte(fmt.Sprintf("%s-%d", blockType, i))}},
+			}
+			require.Eventually(t, func() bool {
+				return client.PutBlock(context.Background(), block) == nil
+			}, 5*time.Second, 100*time.Millisecond)
+		}
+		infos = append(infos, info)
+	}
+
+	p := newPrefetcher("256", client, 4)
+	blocks := p.run(context.Background(), infos)
+	for i := range infos {
+		block := <-blocks
+		<-block.done
+		require.NoErrorf(t, block.err, "fetchBlock error")
+		require.Equal(t, []byte(fmt.Sprintf("HEADER-%d", i)), block.header.BatchItems[0].Data)
+		require.Equal(t, []byte(fmt.Sprintf("DATA-%d", i)), block.data.BatchItems[0].Data)
+		require.LessOrEqual(t, len(p.slots), 4)
+		p.release()
+	}
+	_, ok := <-blocks
+	require.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	blocks = p.run(ctx, infos)
+	block := <-blocks
+	<-block.done
+	p.release()
+	cancel()
+	p.drain(blocks)
+	require.Equal(t, 0, len(p.slots))
+}
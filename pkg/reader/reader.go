@@ -2,10 +2,14 @@ package reader
 
 import (
 	"context"
+	"crypto/ed25519"
 	"sync"
+	"time"
 
+	"github.com/DeBankDeFi/nodex/pkg/checkpoint"
 	"github.com/DeBankDeFi/nodex/pkg/db"
 	"github.com/DeBankDeFi/nodex/pkg/kafka"
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
 	"github.com/DeBankDeFi/nodex/pkg/ndrc"
 	"github.com/DeBankDeFi/nodex/pkg/pb"
 	"github.com/DeBankDeFi/nodex/pkg/s3"
@@ -19,16 +23,28 @@ type Reader struct {
 
 	config *utils.Config
 
-	dbPool     *db.DBPool
-	s3         *s3.Client
-	kafka      *kafka.KafkaClient
-	ndrcReader *ndrc.ReaderClient
-	broker     *broker
-	srv        *grpc.Server
+	dbPool       *db.DBPool
+	s3           *s3.Client
+	bus          kafka.HeaderBus
+	ndrcReader   *ndrc.ReaderClient
+	broker       *broker
+	prefetcher   *prefetcher
+	checkpointer *checkpoint.Checkpointer
+	metrics      *metrics.ReaderMetrics
+	trustedKeys  []ed25519.PublicKey // of the writers, blocks are not verified if empty
+	srv          *grpc.Server
 	pb.UnimplementedRemoteServer
 
 	lastBlockHeader *pb.BlockInfo
+	groupOffset     int64 // last offset committed to the consumer group
+	listing         bool  // auto discovery lists s3 since listingSince
+	listingSince    time.Time
+	listedAt        time.Time // last listing which found no new header
+	checkpointAt    time.Time
+	checkpointing   int32 // 1 while a checkpoint is exported
+	watchCancel     context.CancelFunc
 	resetC          <-chan string
+	rewindC         chan *rewindRequest
 
 	rootCtx   context.Context
 	cancelFn  context.CancelFunc
@@ -54,6 +70,22 @@ func NewReader(config *utils.Config, dbPool *db.DBPool) (reader *Reader, err err
 			return nil, err
 		}
 	}
+	var checkpointer *checkpoint.Checkpointer
+	if config.CheckpointInterval > 0 {
+		prefix := utils.CheckpointPrefix(config.Env, config.ChainId, config.Role, config.CheckpointName)
+		checkpointer = checkpoint.NewCheckpointer(dbPool, s3, prefix)
+	}
+
+	trustedKeys, err := utils.ParsePublicKeys(config.TrustedKeys)
+	if err != nil {
+		return nil, err
+	}
+	readerMetrics := metrics.NewReaderMetrics()
+	err = recoverApply(context.Background(), s3, dbPool, readerMetrics, trustedKeys)
+	if err != nil {
+		utils.Logger().Error("recoverApply error", zap.Error(err))
+		return nil, err
+	}
 
 	lastBlockHeader, err := dbPool.GetBlockInfo()
 	if err != nil {
@@ -77,30 +109,58 @@ func NewReader(config *utils.Config, dbPool *db.DBPool) (reader *Reader, err err
 	}
 
 	if lastBlockHeader.BlockNum != -1 && lastBlockHeader.MsgOffset == -1 {
-		infos, err := s3.ListHeaderStartAt(context.Background(), chainId, env, role,
-			lastBlockHeader.BlockNum-1, 5, -1)
+		last := lastBlockHeader
+		err = s3.ListHeaders(context.Background(), chainId, env, role,
+			last.BlockNum-1, last.BlockNum+2, -1, func(info *pb.BlockInfo) bool {
+				if info.BlockHash == last.BlockHash || info.BlockNum == last.BlockNum {
+					lastBlockHeader = info
+					return false
+				}
+				if info.BlockNum == last.BlockNum+1 {
+					lastBlockHeader.MsgOffset = info.MsgOffset - 1
+					return false
+				}
+				return true
+			})
 		if err != nil {
-			utils.Logger().Error("ListHeaderStartAt error", zap.Error(err))
+			utils.Logger().Error("ListHeaders error", zap.Error(err))
 			return nil, err
 		}
-		for _, info := range infos {
-			if info.BlockHash == lastBlockHeader.BlockHash || info.BlockNum == lastBlockHeader.BlockNum {
-				lastBlockHeader = info
-				break
-			}
-			if info.BlockNum == lastBlockHeader.BlockNum+1 {
-				lastBlockHeader.MsgOffset = info.MsgOffset - 1
-				break
-			}
-		}
 	}
 
 	topic := utils.Topic(env, chainId, role)
 
-	kafka, err := kafka.NewKafkaClient(topic, lastBlockHeader.MsgOffset, config.KafkaAddr)
+	readerLastOffset := lastBlockHeader.MsgOffset
+	offset, ok, err := dbPool.GetTopicOffset(topic, config.KafkaPartition)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		readerLastOffset = offset
+	}
+
+	err = checkDiscovery(config.Discovery)
 	if err != nil {
 		return nil, err
 	}
+	bus, err := kafka.DialHeaderBus(config, topic, readerLastOffset)
+	if err != nil {
+		return nil, err
+	}
+	listing := false
+	err = bus.Sync()
+	if err != nil {
+		if config.Discovery == "" || config.Discovery == DiscoveryKafka {
+			return nil, err
+		}
+		utils.Logger().Warn("header bus unavailable, discover blocks by s3 listing", zap.Error(err))
+		listing = true
+	} else {
+		err = checkGroupOffset(context.Background(), bus, config, readerLastOffset)
+		if err != nil {
+			return nil, err
+		}
+	}
 	ndrcReader, err := ndrc.NewReaderClient(config.NdrcAddr)
 	if err != nil {
 		return nil, err
@@ -110,6 +170,7 @@ func NewReader(config *utils.Config, dbPool *db.DBPool) (reader *Reader, err err
 
 	resetC, err := ndrcReader.WatchRole(rootCtx)
 	if err != nil {
+		cancelFn()
 		return nil, err
 	}
 
@@ -117,11 +178,19 @@ func NewReader(config *utils.Config, dbPool *db.DBPool) (reader *Reader, err err
 		config:          config,
 		dbPool:          dbPool,
 		s3:              s3,
-		kafka:           kafka,
+		bus:             bus,
 		ndrcReader:      ndrcReader,
 		broker:          newBroker(),
+		prefetcher:      newPrefetcher(chainId, s3, config.PrefetchDepth),
+		checkpointer:    checkpointer,
+		metrics:         readerMetrics,
 		lastBlockHeader: lastBlockHeader,
+		trustedKeys:     trustedKeys,
+		groupOffset:     readerLastOffset,
+		listing:         listing,
+		listingSince:    time.Now(),
 		resetC:          resetC,
+		rewindC:         make(chan *rewindRequest),
 		rootCtx:         rootCtx,
 		cancelFn:        cancelFn,
 		stopdoneC:       make(chan struct{}),
@@ -130,11 +199,26 @@ func NewReader(config *utils.Config, dbPool *db.DBPool) (reader *Reader, err err
 }
 
 func (r *Reader) Start() (err error) {
+	r.watch()
 	go r.fetchRun()
 	go r.grpcRun(r.config.RemoteListenAddr)
 	return
 }
 
+// watch makes the s3 client keep the blocks of the current role pushed by the
+// proxies, replacing the watch of the previous role.
+func (r *Reader) watch() {
+	if !r.config.WatchBlocks {
+		return
+	}
+	if r.watchCancel != nil {
+		r.watchCancel()
+	}
+	ctx, cancel := context.WithCancel(r.rootCtx)
+	r.watchCancel = cancel
+	go r.s3.WatchBlocks(ctx, r.config.Env, r.config.ChainId, r.config.Role)
+}
+
 func (r *Reader) Stop() {
 	r.Lock()
 	defer r.Unlock()
@@ -0,0 +1,174 @@
+package reader
+
+import (
+	"context"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rewindRequest asks fetchRun to rewind the DBs to blockNum, so it never runs
+// concurrently with the application of blocks.
+type rewindRequest struct {
+	blockNum int64
+	info     *pb.BlockInfo
+	err      error
+	done     chan struct{}
+}
+
+// checkReorg compares info with the applied chain. A block below the applied
+// height which is already applied is reported so it can be skipped, a block
+// at or below the applied height with a different hash forks the chain, the
+// DBs are rolled back to its parent so it can be applied on top.
+func (r *Reader) checkReorg(info *pb.BlockInfo) (applied bool, err error) {
+	last := r.lastBlockHeader
+	if info.BlockNum > last.BlockNum {
+		return false, nil
+	}
+	appliedHash := last.BlockHash
+	if info.BlockNum < last.BlockNum {
+		undo, err := r.dbPool.GetUndoLog(info.BlockNum)
+		if err != nil {
+			return false, err
+		}
+		if undo == nil {
+			utils.Logger().Error("checkReorg undo log not found", zap.Int64("BlockNum", info.BlockNum),
+				zap.Int64("lastBlockNum", last.BlockNum))
+			return false, utils.ErrReorgTooDeep
+		}
+		appliedHash = undo.Info.BlockHash
+	}
+	if appliedHash == info.BlockHash {
+		// the applied head may be broadcast again by a recovering writer,
+		// re-applying it is idempotent.
+		return info.BlockNum < last.BlockNum, nil
+	}
+	depth := last.BlockNum - info.BlockNum + 1
+	if depth > int64(r.config.ReorgDeep) {
+		utils.Logger().Error("checkReorg reorg too deep", zap.Int64("depth", depth), zap.Int("reorgDeep", r.config.ReorgDeep))
+		return false, utils.ErrReorgTooDeep
+	}
+	utils.Logger().Warn("chain reorg", zap.Int64("depth", depth), zap.Int64("BlockNum", info.BlockNum),
+		zap.String("appliedHash", appliedHash), zap.String("BlockHash", info.BlockHash))
+	r.metrics.ObserveReorgDepth(r.config.ChainId, depth)
+	return false, r.rollback(info.BlockNum - 1)
+}
+
+// rollback reverts the applied blocks above blockNum using their UndoLogs.
+// Each block is journaled before it is reverted, an interrupted rollback
+// leaves the DBs at that block after recoverApply.
+func (r *Reader) rollback(blockNum int64) error {
+	for r.lastBlockHeader.BlockNum > blockNum {
+		undo, err := r.dbPool.GetUndoLog(r.lastBlockHeader.BlockNum)
+		if err != nil {
+			return err
+		}
+		if undo == nil {
+			utils.Logger().Error("rollback undo log not found", zap.Int64("BlockNum", r.lastBlockHeader.BlockNum))
+			return utils.ErrReorgTooDeep
+		}
+		err = r.dbPool.WriteApplyJournal(undo.Info, nil)
+		if err != nil {
+			return err
+		}
+		err = r.dbPool.UndoBlock(undo)
+		if err != nil {
+			utils.Logger().Error("UndoBlock error", zap.Error(err), zap.Int64("BlockNum", undo.Info.BlockNum))
+			return err
+		}
+		if undo.Prev != nil {
+			r.lastBlockHeader = undo.Prev
+		} else {
+			r.lastBlockHeader, err = r.dbPool.GetBlockInfo()
+			if err != nil {
+				return err
+			}
+		}
+		utils.Logger().Info("rollback block success", zap.Int64("BlockNum", undo.Info.BlockNum),
+			zap.String("BlockHash", undo.Info.BlockHash))
+	}
+	return nil
+}
+
+// undoLog returns the UndoLog to record for info, an applied block which is
+// applied again keeps the UndoLog recorded the first time.
+func (r *Reader) undoLog(info *pb.BlockInfo, headerFile *pb.Block, blockFile *pb.Block) (*pb.UndoLog, error) {
+	if r.undoWindow() <= 0 {
+		return nil, nil
+	}
+	undo, err := r.dbPool.GetUndoLog(info.BlockNum)
+	if err != nil {
+		return nil, err
+	}
+	if undo != nil && undo.Info.BlockHash == info.BlockHash {
+		return undo, nil
+	}
+	return r.dbPool.BuildUndoLog(info, blockItems(headerFile, blockFile))
+}
+
+// undoWindow returns the number of latest blocks whose UndoLogs are kept, at
+// least ReorgDeep so reorgs can always be rolled back.
+func (r *Reader) undoWindow() int {
+	if r.config.UndoWindow > r.config.ReorgDeep {
+		return r.config.UndoWindow
+	}
+	return r.config.ReorgDeep
+}
+
+// rewind reverts the applied blocks above blockNum and resets the Kafka
+// offset, the reverted blocks are applied again by fetchAndCommit.
+func (r *Reader) rewind(blockNum int64) (*pb.BlockInfo, error) {
+	if blockNum < 0 {
+		return nil, utils.ErrReorgTooDeep
+	}
+	for n := r.lastBlockHeader.BlockNum; n > blockNum; n-- {
+		undo, err := r.dbPool.GetUndoLog(n)
+		if err != nil {
+			return nil, err
+		}
+		if undo == nil {
+			utils.Logger().Error("rewind undo log not found", zap.Int64("BlockNum", n), zap.Int64("to", blockNum))
+			return nil, utils.ErrReorgTooDeep
+		}
+	}
+	err := r.rollback(blockNum)
+	if err != nil {
+		return nil, err
+	}
+	err = r.dbPool.WriteTopicOffset(r.topicOffset(r.lastBlockHeader.MsgOffset))
+	if err != nil {
+		return nil, err
+	}
+	r.bus.ResetLastReaderOffset(r.lastBlockHeader.MsgOffset)
+	utils.Logger().Info("rewind success", zap.Any("lastBlockHeader", r.lastBlockHeader))
+	return r.lastBlockHeader, nil
+}
+
+// Rewind reverts the DBs to the state after block req.BlockNum was applied,
+// blocks within the undo window can be rewound.
+func (r *Reader) Rewind(ctx context.Context, req *pb.RewindRequest) (*pb.RewindReply, error) {
+	utils.Logger().Info("Rewind", zap.Any("req", req))
+	rewindReq := &rewindRequest{
+		blockNum: req.BlockNum,
+		done:     make(chan struct{}),
+	}
+	select {
+	case r.rewindC <- rewindReq:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.rootCtx.Done():
+		return nil, status.Errorf(utils.RemoteErrorCode, "reader stopped")
+	}
+	<-rewindReq.done
+	if rewindReq.err != nil {
+		code := utils.RemoteErrorCode
+		if errorCode, ok := rewindReq.err.(*utils.ErrorCode); ok {
+			code = errorCode.Code()
+		}
+		return nil, status.Errorf(codes.Code(code), "Rewind failed, err : %v", rewindReq.err)
+	}
+	return &pb.RewindReply{Info: rewindReq.info}, nil
+}
@@ -0,0 +1,147 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/kafka"
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorgAndRewind(t *testing.T) {
+	ldb, err := db.NewLDB(t.TempDir(), 16)
+	require.NoErrorf(t, err, "NewLDB error")
+	pool := db.NewDBPool()
+	pool.Register(0, "test", "leveldb", ldb, true)
+	defer pool.Close()
+	bus, err := kafka.NewMemoryBus("reorg", 0, -1)
+	require.NoErrorf(t, err, "NewMemoryBus error")
+	r := &Reader{
+		config:          &utils.Config{ReorgDeep: 2, UndoWindow: 4},
+		dbPool:          pool,
+		bus:             bus,
+		metrics:         metrics.NewReaderMetrics(),
+		lastBlockHeader: &pb.BlockInfo{BlockNum: -1, MsgOffset: -1},
+	}
+	apply := func(blockNum int64, hash string) bool {
+		info := &pb.BlockInfo{BlockNum: blockNum, BlockHash: hash, MsgOffset: r.lastBlockHeader.MsgOffset + 1}
+		applied, err := r.checkReorg(info)
+		require.NoErrorf(t, err, "checkReorg error")
+		if applied {
+			return false
+		}
+		batch := ldb.NewBatch()
+		batch.Put([]byte(fmt.Sprintf("block/%d", blockNum)), []byte(hash))
+		batch.Put([]byte("head"), []byte(hash))
+		items, err := pool.Marshal([]db.BatchWithID{{ID: 0, B: batch}})
+		require.NoErrorf(t, err, "Marshal error")
+		header := &pb.Block{Info: info, BatchItems: items}
+		undo, err := r.undoLog(info, header, nil)
+		require.NoErrorf(t, err, "undoLog error")
+		require.NoErrorf(t, commitBlock(pool, info, header, nil, undo), "commitBlock error")
+		require.NoErrorf(t, pool.PruneUndoLogs(info.BlockNum-int64(r.undoWindow())+1), "PruneUndoLogs error")
+		r.lastBlockHeader = info
+		return true
+	}
+	get := func(key string) string {
+		val, _ := ldb.Get([]byte(key))
+		return string(val)
+	}
+
+	for i := int64(0); i < 6; i++ {
+		require.True(t, apply(i, fmt.Sprintf("a%d", i)))
+	}
+	// an applied block delivered again is skipped.
+	require.False(t, apply(4, "a4"))
+	require.Equal(t, "a5", get("head"))
+
+	// a fork at 4 rolls back blocks 4 and 5.
+	require.True(t, apply(4, "b4"))
+	require.Equal(t, "b4", get("head"))
+	require.Equal(t, "", get("block/5"))
+
+	// a fork deeper than ReorgDeep fails.
+	_, err = r.checkReorg(&pb.BlockInfo{BlockNum: 2, BlockHash: "b2"})
+	require.ErrorIs(t, err, utils.ErrReorgTooDeep)
+
+	// rewind is bounded by the undo window.
+	_, err = r.rewind(0)
+	require.ErrorIs(t, err, utils.ErrReorgTooDeep)
+	info, err := r.rewind(1)
+	require.NoErrorf(t, err, "rewind error")
+	require.Equal(t, "a1", info.BlockHash)
+	require.Equal(t, "a1", get("head"))
+	require.Equal(t, "", get("block/2"))
+	require.Equal(t, info.MsgOffset, r.bus.LastReaderOffset())
+}
+
+func TestResetRollback(t *testing.T) {
+	go s3.ListenAndServe("0.0.0.0:8784", 32, s3.NewMemStore())
+	client, err := s3.NewClient("0.0.0.0:8784")
+	require.NoErrorf(t, err, "NewClient error")
+	ldb, err := db.NewLDB(t.TempDir(), 16)
+	require.NoErrorf(t, err, "NewLDB error")
+	pool := db.NewDBPool()
+	pool.Register(0, "test", "leveldb", ldb, true)
+	defer pool.Close()
+	bus, err := kafka.NewMemoryBus("reset", 0, -1)
+	require.NoErrorf(t, err, "NewMemoryBus error")
+	r := &Reader{
+		config:          &utils.Config{ChainId: "256", Env: "test", Role: "master", ReorgDeep: 2, UndoWindow: 4},
+		dbPool:          pool,
+		s3:              client,
+		bus:             bus,
+		metrics:         metrics.NewReaderMetrics(),
+		lastBlockHeader: &pb.BlockInfo{BlockNum: -1, MsgOffset: -1},
+		rootCtx:         context.Background(),
+	}
+	for i := int64(0); i < 4; i++ {
+		info := &pb.BlockInfo{BlockNum: i, BlockHash: fmt.Sprintf("a%d", i), MsgOffset: i}
+		batch := ldb.NewBatch()
+		batch.Put([]byte(fmt.Sprintf("block/%d", i)), []byte(info.BlockHash))
+		batch.Put([]byte("head"), []byte(info.BlockHash))
+		items, err := pool.Marshal([]db.BatchWithID{{ID: 0, B: batch}})
+		require.NoErrorf(t, err, "Marshal error")
+		header := &pb.Block{Info: info, BatchItems: items}
+		undo, err := r.undoLog(info, header, nil)
+		require.NoErrorf(t, err, "undoLog error")
+		require.NoErrorf(t, commitBlock(pool, info, header, nil, undo), "commitBlock error")
+		r.lastBlockHeader = info
+	}
+	put := func(role string, hashes ...string) {
+		for i, hash := range hashes {
+			block := &pb.Block{Info: &pb.BlockInfo{ChainId: "256", Env: "test", Role: role, BlockNum: int64(i),
+				BlockHash: hash, MsgOffset: int64(10 + i), BlockType: pb.BlockInfo_HEADER}}
+			require.Eventually(t, func() bool {
+				return client.PutBlock(context.Background(), block) == nil
+			}, 5*time.Second, 100*time.Millisecond)
+		}
+	}
+	get := func(key string) string {
+		val, _ := ldb.Get([]byte(key))
+		return string(val)
+	}
+
+	// a role without a common ancestor within ReorgDeep is refused.
+	put("other", "c0", "c1", "c2", "c3")
+	require.ErrorIs(t, r.reset("other"), utils.ErrReorgTooDeep)
+	require.Equal(t, "master", r.config.Role)
+	require.Equal(t, "a3", get("head"))
+
+	// a role forked at 2 rolls back blocks 2 and 3.
+	put("slave", "a0", "a1", "b2", "b3")
+	require.NoErrorf(t, r.reset("slave"), "reset error")
+	require.Equal(t, "slave", r.config.Role)
+	require.Equal(t, "a1", r.lastBlockHeader.BlockHash)
+	require.Equal(t, int64(11), r.lastBlockHeader.MsgOffset)
+	require.Equal(t, int64(11), r.bus.LastReaderOffset())
+	require.Equal(t, "a1", get("head"))
+	require.Equal(t, "", get("block/2"))
+}
@@ -0,0 +1,48 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+)
+
+// Checksum returns the sha256 of an object stored in s3.
+func Checksum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// newChecksumHash returns the hash of Checksum to checksum a streamed object.
+func newChecksumHash() hash.Hash {
+	return sha256.New()
+}
+
+// GetChecksum returns the expected checksum of the object info points to,
+// the header and data objects of a block share one BlockInfo.
+func GetChecksum(info *pb.BlockInfo) []byte {
+	if info.BlockType == pb.BlockInfo_DATA {
+		return info.DataChecksum
+	}
+	return info.HeaderChecksum
+}
+
+// SetChecksum records the checksum of the object info points to.
+func SetChecksum(info *pb.BlockInfo, sum []byte) {
+	if info.BlockType == pb.BlockInfo_DATA {
+		info.DataChecksum = sum
+	} else {
+		info.HeaderChecksum = sum
+	}
+}
+
+// VerifyChecksum checks data against the checksum recorded in info, objects
+// written before checksums were introduced have none and always pass.
+func VerifyChecksum(info *pb.BlockInfo, data []byte) bool {
+	expected := GetChecksum(info)
+	if len(expected) == 0 {
+		return true
+	}
+	return bytes.Equal(expected, Checksum(data))
+}
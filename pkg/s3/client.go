@@ -3,81 +3,178 @@ package s3
 import (
 	"bytes"
 	"context"
+	"hash"
 	"io"
 	"math"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DeBankDeFi/nodex/pkg/metrics"
 	"github.com/DeBankDeFi/nodex/pkg/pb"
 	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/proto"
 )
 
+// watchRetry is the interval a proxy is watched again at after an error.
+const watchRetry = time.Second
+
+// Client reads and writes blocks through a set of S3 proxies. Blocks and
+// files are routed to proxies by consistent hashing of their keys so the
+// proxies partition their caches, the headers of a chain are put and listed
+// through the owner of the chain to keep one writer of its header index. A
+// request fails over to the next proxy of the key on errors, but for the
+// header puts and removals of a chain which fail while its owner is down.
 type Client struct {
-	s3client pb.S3ProxyClient
-	conn     *grpc.ClientConn
+	proxies  []*proxy
+	ring     *hashRing
 	cache    *utils.Cache
-	addr     string
+	pushed   *utils.Cache // of the stored objects pushed by WatchBlocks
+	watching int32
 	s3Metric *metrics.S3Metrics
+
+	compression pb.BlockInfo_Compression
+	// replicas is the number of proxies following the owner of a block
+	// whose caches PutBlock warms.
+	replicas int
 }
 
-func NewClient(addr string) (*Client, error) {
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+type proxy struct {
+	sync.Mutex
+	addr   string
+	conn   *grpc.ClientConn
+	client pb.S3ProxyClient
+}
+
+func dialProxy(addr string) (*grpc.ClientConn, error) {
+	return grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32),
 			grpc.MaxCallSendMsgSize(math.MaxInt32)))
+}
+
+// get returns the client of the proxy, redialing a broken connection.
+func (p *proxy) get() (pb.S3ProxyClient, error) {
+	p.Lock()
+	defer p.Unlock()
+	if p.conn != nil {
+		if utils.CheckConnState(p.conn) == nil {
+			return p.client, nil
+		}
+		p.conn.Close()
+	}
+	conn, err := dialProxy(p.addr)
+	if err != nil {
+		p.conn = nil
+		return nil, err
+	}
+	p.conn = conn
+	p.client = pb.NewS3ProxyClient(conn)
+	return p.client, nil
+}
+
+// NewClient creates a Client of the proxies at addr, a comma separated list
+// of addresses parsed by ParseProxyAddrs.
+func NewClient(addr string) (*Client, error) {
+	addrs, err := ParseProxyAddrs(addr)
 	if err != nil {
 		return nil, err
 	}
+	proxies := make([]*proxy, 0, len(addrs))
+	for _, addr := range addrs {
+		conn, err := dialProxy(addr)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, &proxy{addr: addr, conn: conn, client: pb.NewS3ProxyClient(conn)})
+	}
 	return &Client{
-		conn:     conn,
-		s3client: pb.NewS3ProxyClient(conn),
-		cache:    utils.NewCache(MaxCacheSize),
-		addr:     addr,
+		proxies:  proxies,
+		ring:     newHashRing(addrs),
+		cache:    utils.NewCache("s3-client", MaxCacheSize, MaxCacheBytes),
+		pushed:   utils.NewCache("s3-client-push", MaxCacheSize, MaxCacheBytes),
 		s3Metric: metrics.NewS3Metrics(),
 	}, nil
 }
 
+// SetCompression sets the codec used to compress blocks in PutBlock.
+func (c *Client) SetCompression(codec pb.BlockInfo_Compression) {
+	c.compression = codec
+}
+
+// SetReplicas sets the number of proxies following the owner of a block
+// whose caches PutBlock warms.
+func (c *Client) SetReplicas(replicas int) {
+	c.replicas = replicas
+}
+
+// GetConn returns the connection of the first proxy.
 func (c *Client) GetConn() *grpc.ClientConn {
-	return c.conn
+	c.proxies[0].Lock()
+	defer c.proxies[0].Unlock()
+	return c.proxies[0].conn
 }
 
+// ResetConn redials the broken connections of the proxies.
 func (c *Client) ResetConn() error {
-	if c.conn != nil {
-		if utils.CheckConnState(c.conn) == nil {
-			return nil
+	for _, p := range c.proxies {
+		if _, err := p.get(); err != nil {
+			return err
 		}
-		c.conn.Close()
 	}
-	conn, err := grpc.Dial(c.addr, grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32),
-			grpc.MaxCallSendMsgSize(math.MaxInt32)))
-	if err != nil {
-		return err
-	}
-	c.s3client = pb.NewS3ProxyClient(conn)
 	return nil
 }
 
-func (c *Client) GetBlock(ctx context.Context, info *pb.BlockInfo, noCache bool) (header *pb.Block, err error) {
-	err = c.ResetConn()
-	if err != nil {
-		return nil, err
+// do calls fn with the proxies of key in order until it succeeds or ctx is
+// done.
+func (c *Client) do(ctx context.Context, key string, fn func(p *proxy, client pb.S3ProxyClient) error) error {
+	return c.try(ctx, key, c.ring.lookup(key), fn)
+}
+
+// doOwner calls fn with the owner of key only, the header index of a chain
+// is written by its owner alone so its writes are not failed over.
+func (c *Client) doOwner(ctx context.Context, key string, fn func(p *proxy, client pb.S3ProxyClient) error) error {
+	return c.try(ctx, key, c.ring.lookup(key)[:1], fn)
+}
+
+func (c *Client) try(ctx context.Context, key string, proxies []int, fn func(p *proxy, client pb.S3ProxyClient) error) (err error) {
+	for _, i := range proxies {
+		p := c.proxies[i]
+		var client pb.S3ProxyClient
+		client, err = p.get()
+		if err == nil {
+			err = fn(p, client)
+			if err == nil {
+				return nil
+			}
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		utils.Logger().Warn("s3 proxy error", zap.String("addr", p.addr), zap.String("key", key), zap.Error(err))
+		c.s3Metric.IncreaseProxyErrors(p.addr)
 	}
+	return err
+}
+
+func (c *Client) GetBlock(ctx context.Context, info *pb.BlockInfo, noCache bool) (header *pb.Block, err error) {
 	commonPrefix := utils.CommonPrefix(info.Env, info.ChainId, info.Role, info.BlockType)
 	lru := c.cache.GetOrCreatePrefixCache(commonPrefix)
 	key := utils.InfoToPrefix(info)
 	startTime := time.Now()
-	if noCache {
-		val, err := c.getBlock(ctx, info, noCache)
+	if block, ok := c.pushedBlock(info, key); ok {
+		header = block
+	} else if noCache {
+		val, err := c.fetchBlock(ctx, key, info, noCache)
 		if err != nil {
 			return nil, err
 		}
 		header = val
-		lru.Insert(key, header, info.BlockNum)
+		lru.Insert(key, proto.Clone(header), info.BlockNum)
 	} else {
-		val, err := lru.Get(key, info.BlockNum, func() (interface{}, error) { return c.getBlock(ctx, info, noCache) })
+		val, err := lru.Get(key, info.BlockNum, func() (interface{}, error) { return c.fetchBlock(ctx, key, info, noCache) })
 		if err != nil {
 			return nil, err
 		}
@@ -88,123 +185,484 @@ func (c *Client) GetBlock(ctx context.Context, info *pb.BlockInfo, noCache bool)
 	return header, nil
 }
 
-func (c *Client) getBlock(ctx context.Context, info *pb.BlockInfo, noCache bool) (header *pb.Block, err error) {
-	client, err := c.s3client.GetBlock(ctx, &pb.GetBlockRequest{
+// fetchBlock gets the block at key from its proxies, a download failing over
+// to another proxy resumes from the last received byte.
+func (c *Client) fetchBlock(ctx context.Context, key string, info *pb.BlockInfo, noCache bool) (block *pb.Block, err error) {
+	buf := new(bytes.Buffer)
+	err = c.do(ctx, key, func(p *proxy, s3client pb.S3ProxyClient) error {
+		block, err = c.getBlock(ctx, s3client, info, noCache, buf)
+		return err
+	})
+	return block, err
+}
+
+// getBlock downloads the rest of the block of info to buf, an interrupted
+// download is resumed from the last received byte as long as it progresses.
+func (c *Client) getBlock(ctx context.Context, s3client pb.S3ProxyClient, info *pb.BlockInfo, noCache bool, buf *bytes.Buffer) (header *pb.Block, err error) {
+	for {
+		offset := buf.Len()
+		err = c.recvBlock(ctx, s3client, info, noCache, buf)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil || buf.Len() == offset {
+			return nil, err
+		}
+		utils.Logger().Warn("getBlock interrupted, resuming", zap.Any("info", info), zap.Int("offset", buf.Len()), zap.Error(err))
+	}
+	if buf.Len() == 0 && len(GetChecksum(info)) == 0 {
+		return nil, nil
+	}
+	if !VerifyChecksum(info, buf.Bytes()) {
+		c.s3Metric.IncreaseChecksumMismatch("s3-proxy")
+		utils.Logger().Error("getBlock checksum mismatch", zap.Any("info", info), zap.Int("size", buf.Len()))
+		// the next proxy downloads the block again.
+		buf.Reset()
+		return nil, utils.ErrChecksumMismatch
+	}
+	return decodeBlock(info.Compression, buf.Bytes())
+}
+
+// recvBlock appends the block of info from the end of buf to buf.
+func (c *Client) recvBlock(ctx context.Context, s3client pb.S3ProxyClient, info *pb.BlockInfo, noCache bool, buf *bytes.Buffer) error {
+	client, err := s3client.GetBlock(ctx, &pb.GetBlockRequest{
 		Info:    info,
 		NoCache: noCache,
+		Offset:  int64(buf.Len()),
 	})
 	if err != nil {
-		return nil, err
+		return err
 	}
-	buf := new(bytes.Buffer)
 	for {
 		chunk, err := client.Recv()
 		if err != nil {
 			if err == io.EOF {
-				break
+				return nil
 			}
-			return nil, err
+			return err
 		}
 		if len(chunk.Chunk) > 0 {
 			buf.Write(chunk.Chunk)
 		}
 	}
-	client.CloseSend()
-	if buf.Len() == 0 {
-		return nil, nil
+}
+
+// decodeBlock decompresses buf with codec, as recorded in the info of the
+// block, and unmarshals it.
+func decodeBlock(codec pb.BlockInfo_Compression, buf []byte) (block *pb.Block, err error) {
+	data, err := Decompress(codec, buf)
+	if err != nil {
+		return nil, err
 	}
-	header = &pb.Block{}
-	err = proto.Unmarshal(buf.Bytes(), header)
+	block = &pb.Block{}
+	err = proto.Unmarshal(data, block)
 	if err != nil {
 		return nil, err
 	}
-	return header, nil
+	return block, nil
 }
 
-func (c *Client) PutBlock(ctx context.Context, block *pb.Block) (err error) {
-	startTime := time.Now()
-	err = c.ResetConn()
-	if err != nil {
-		return err
-	}
-	data, err := proto.Marshal(block)
-	if err != nil {
-		return err
+// WatchBlocks keeps the blocks of the chain pushed by the proxies until ctx
+// is done, GetBlock then returns them without downloading them. A proxy is
+// watched again watchRetry after an error.
+func (c *Client) WatchBlocks(ctx context.Context, env, chainId, role string) {
+	atomic.AddInt32(&c.watching, 1)
+	defer atomic.AddInt32(&c.watching, -1)
+	req := &pb.WatchBlocksRequest{Env: env, ChainId: chainId, Role: role}
+	wg := sync.WaitGroup{}
+	for _, p := range c.proxies {
+		wg.Add(1)
+		go func(p *proxy) {
+			defer wg.Done()
+			for {
+				err := c.watch(ctx, p, req)
+				if ctx.Err() != nil {
+					return
+				}
+				utils.Logger().Warn("WatchBlocks error", zap.String("addr", p.addr), zap.Error(err))
+				select {
+				case <-time.After(watchRetry):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(p)
 	}
-	client, err := c.s3client.PutBlock(ctx)
+	wg.Wait()
+}
+
+func (c *Client) watch(ctx context.Context, p *proxy, req *pb.WatchBlocksRequest) error {
+	s3client, err := p.get()
 	if err != nil {
 		return err
 	}
-	block.Info.BlockSize = int64(len(data))
-	err = client.Send(&pb.BlockChunk{
-		Info: block.Info,
-	})
+	stream, err := s3client.WatchBlocks(ctx, req)
 	if err != nil {
 		return err
 	}
-	chunk := make([]byte, ChunkSize)
-	reader := bytes.NewReader(data)
 	for {
-		n, err := reader.Read(chunk)
+		rsp, err := stream.Recv()
 		if err != nil {
-			if err == io.EOF {
-				break
-			}
 			return err
 		}
-		if err := client.Send(&pb.BlockChunk{Chunk: chunk[:n]}); err != nil {
-			return err
+		if !VerifyChecksum(rsp.Info, rsp.Data) {
+			c.s3Metric.IncreaseChecksumMismatch("s3-proxy")
+			utils.Logger().Error("WatchBlocks checksum mismatch", zap.Any("info", rsp.Info), zap.Int("size", len(rsp.Data)))
+			continue
 		}
+		lru := c.pushed.GetOrCreatePrefixCache(utils.CommonPrefix(rsp.Info.Env, rsp.Info.ChainId, rsp.Info.Role, rsp.Info.BlockType))
+		lru.Insert(utils.InfoToPrefix(rsp.Info), rsp.Data, rsp.Info.BlockNum)
 	}
-	_, err = client.CloseAndRecv()
+}
+
+// pushedBlock returns the block of info pushed by WatchBlocks, if any.
+func (c *Client) pushedBlock(info *pb.BlockInfo, key string) (*pb.Block, bool) {
+	if atomic.LoadInt32(&c.watching) == 0 {
+		return nil, false
+	}
+	lru := c.pushed.GetOrCreatePrefixCache(utils.CommonPrefix(info.Env, info.ChainId, info.Role, info.BlockType))
+	val, ok := lru.Peek(key)
+	if !ok || !VerifyChecksum(info, val.([]byte)) {
+		return nil, false
+	}
+	block, err := decodeBlock(info.Compression, val.([]byte))
 	if err != nil {
+		utils.Logger().Error("pushed block decode error", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	return block, true
+}
+
+// PutBlock streams the block through the owner of its key, or of its chain
+// for a header, then warms the caches of the replicas of its key. The block
+// is compressed and sent as it is marshalled, so it is not copied.
+func (c *Client) PutBlock(ctx context.Context, block *pb.Block) (err error) {
+	_, err = c.PutBlockInfo(ctx, block)
+	return err
+}
+
+// PutBlockInfo puts block as PutBlock and returns the info of the stored
+// object, with its compression, checksum and size. block is not modified.
+func (c *Client) PutBlockInfo(ctx context.Context, block *pb.Block) (stored *pb.BlockInfo, err error) {
+	startTime := time.Now()
+	// the stored block keeps its info without its own checksum.
+	info := proto.Clone(block.Info).(*pb.BlockInfo)
+	info.Compression = c.compression
+	SetChecksum(info, nil)
+	key := utils.InfoToPrefix(info)
+	var writer *proxy
+	var sum []byte
+	var size int64
+	put := func(p *proxy, s3client pb.S3ProxyClient) (err error) {
+		writer = p
+		sum, size, err = putBlock(ctx, s3client, info, block.BatchItems, c.compression, false)
 		return err
 	}
+	if info.BlockType == pb.BlockInfo_HEADER {
+		err = c.doOwner(ctx, utils.TopicPrefix(info.Env, info.ChainId, info.Role), put)
+	} else {
+		err = c.do(ctx, key, put)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// proxies do not cache blocks above PartSize.
+	if size <= int64(PartSize) {
+		c.warm(ctx, key, writer, info, block.BatchItems)
+	}
 	c.s3Metric.ObserveWriteLatency("s3-proxy", float64(time.Since(startTime).Milliseconds()))
-	c.s3Metric.IncreaseWriteSize("s3-proxy", int64(proto.Size(block)))
-	return nil
+	c.s3Metric.IncreaseWriteSize("s3-proxy", size)
+	stored = proto.Clone(info).(*pb.BlockInfo)
+	SetChecksum(stored, sum)
+	stored.BlockSize = size
+	return stored, nil
+}
+
+// warm puts the block in the caches of the replicas of key but writer.
+func (c *Client) warm(ctx context.Context, key string, writer *proxy, info *pb.BlockInfo, items []*pb.Data) {
+	proxies := c.ring.lookup(key)
+	if len(proxies) > c.replicas+1 {
+		proxies = proxies[:c.replicas+1]
+	}
+	wg := sync.WaitGroup{}
+	for _, i := range proxies {
+		p := c.proxies[i]
+		if p == writer {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s3client, err := p.get()
+			if err == nil {
+				_, _, err = putBlock(ctx, s3client, info, items, c.compression, true)
+			}
+			if err != nil {
+				utils.Logger().Warn("s3 proxy warm error", zap.String("addr", p.addr), zap.String("key", key), zap.Error(err))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// chunkWriter sends the bytes written to it in chunks of ChunkSize.
+type chunkWriter struct {
+	stream pb.S3Proxy_PutBlockClient
+	buf    []byte
+	hash   hash.Hash
+	size   int64
+}
+
+func (w *chunkWriter) Write(p []byte) (n int, err error) {
+	w.hash.Write(p)
+	w.size += int64(len(p))
+	for len(p) > 0 {
+		m := ChunkSize - len(w.buf)
+		if m > len(p) {
+			m = len(p)
+		}
+		w.buf = append(w.buf, p[:m]...)
+		p = p[m:]
+		n += m
+		if len(w.buf) == ChunkSize {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
 }
 
-func (c *Client) ListHeaderStartAt(ctx context.Context, chainId, env, role string, blockNum int64, count int64, after int64) (info []*pb.BlockInfo, err error) {
-	err = c.ResetConn()
+func (w *chunkWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.stream.Send(&pb.BlockChunk{Chunk: w.buf})
+	w.buf = w.buf[:0]
+	return err
+}
+
+// putBlock streams the block of info and items compressed with codec, the
+// checksum and size of the stored object are sent in a last info once
+// streamed and returned.
+func putBlock(ctx context.Context, s3client pb.S3ProxyClient, info *pb.BlockInfo, items []*pb.Data,
+	codec pb.BlockInfo_Compression, cacheOnly bool) (sum []byte, size int64, err error) {
+	// the proxy aborts the upload when the stream is canceled on errors.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := s3client.PutBlock(ctx)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	rsp, err := c.s3client.ListHeaderStartAt(ctx, &pb.ListHeaderStartAtRequest{
-		ChainId:        chainId,
-		Env:            env,
-		Role:           role,
-		BlockNum:       blockNum,
-		CountNum:       count,
-		AfterMsgOffset: after,
+	err = stream.Send(&pb.BlockChunk{
+		Info:      info,
+		CacheOnly: cacheOnly,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	w := &chunkWriter{stream: stream, buf: make([]byte, 0, ChunkSize), hash: newChecksumHash()}
+	cw, err := NewCompressWriter(codec, w)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = marshalBlockTo(cw, info, items)
+	if err != nil {
+		cw.Close()
+		return nil, 0, err
+	}
+	err = cw.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	err = w.flush()
+	if err != nil {
+		return nil, 0, err
+	}
+	last := proto.Clone(info).(*pb.BlockInfo)
+	SetChecksum(last, w.hash.Sum(nil))
+	last.BlockSize = w.size
+	err = stream.Send(&pb.BlockChunk{Info: last})
+	if err != nil {
+		return nil, 0, err
+	}
+	_, err = stream.CloseAndRecv()
+	if err != nil {
+		return nil, 0, err
+	}
+	return GetChecksum(last), last.BlockSize, nil
+}
+
+func (c *Client) ListHeaderStartAt(ctx context.Context, chainId, env, role string, blockNum int64, count int64, after int64) (infos []*pb.BlockInfo, err error) {
+	err = c.do(ctx, utils.TopicPrefix(env, chainId, role), func(p *proxy, s3client pb.S3ProxyClient) error {
+		rsp, err := s3client.ListHeaderStartAt(ctx, &pb.ListHeaderStartAtRequest{
+			ChainId:        chainId,
+			Env:            env,
+			Role:           role,
+			BlockNum:       blockNum,
+			CountNum:       count,
+			AfterMsgOffset: after,
+		})
+		if err != nil {
+			return err
+		}
+		infos = rsp.Infos
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return rsp.Infos, nil
+	return infos, nil
 }
 
-func (c *Client) RemoveFiles(ctx context.Context, infos []*pb.BlockInfo) error {
-	_, err := c.s3client.RemoveFiles(ctx, &pb.RemoveFilesRequest{
-		Infos: infos,
+// ListHeaders calls fn with the headers from start to end, or to the latest
+// if end is 0, whose msg offset is greater than after, in key order. The
+// listing stops when fn returns false.
+func (c *Client) ListHeaders(ctx context.Context, chainId, env, role string, start, end, after int64, fn func(info *pb.BlockInfo) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	listed := false
+	var listErr error
+	err := c.do(ctx, utils.TopicPrefix(env, chainId, role), func(p *proxy, s3client pb.S3ProxyClient) error {
+		stream, err := s3client.ListHeaders(ctx, &pb.ListHeadersRequest{
+			ChainId:        chainId,
+			Env:            env,
+			Role:           role,
+			StartBlockNum:  start,
+			EndBlockNum:    end,
+			AfterMsgOffset: after,
+		})
+		if err != nil {
+			return err
+		}
+		for {
+			rsp, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				if listed {
+					// fn already saw a part of the listing, do not fail over.
+					listErr = err
+					return nil
+				}
+				return err
+			}
+			for _, info := range rsp.Infos {
+				listed = true
+				if !fn(info) {
+					return nil
+				}
+			}
+		}
 	})
+	if err != nil {
+		return err
+	}
+	return listErr
+}
+
+func (c *Client) RemoveFiles(ctx context.Context, infos []*pb.BlockInfo) error {
+	_, err := c.RemoveFilesBefore(ctx, infos, time.Time{}, false)
 	return err
 }
 
+// RemoveFilesBefore removes the objects of infos last modified before
+// modifiedBefore, or all of them if it is zero. With dryRun nothing is
+// removed and the reply reports what would be. The infos of a chain are
+// removed through its owner, which indexes its headers.
+func (c *Client) RemoveFilesBefore(ctx context.Context, infos []*pb.BlockInfo, modifiedBefore time.Time, dryRun bool) (*pb.RemoveFilesReply, error) {
+	var prefixes []string
+	chains := make(map[string][]*pb.BlockInfo)
+	for _, info := range infos {
+		prefix := utils.TopicPrefix(info.Env, info.ChainId, info.Role)
+		if _, ok := chains[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+		chains[prefix] = append(chains[prefix], info)
+	}
+	reply := &pb.RemoveFilesReply{}
+	for _, prefix := range prefixes {
+		req := &pb.RemoveFilesRequest{
+			Infos:  chains[prefix],
+			DryRun: dryRun,
+		}
+		if !modifiedBefore.IsZero() {
+			req.ModifiedBefore = modifiedBefore.Unix()
+		}
+		var rsp *pb.RemoveFilesReply
+		err := c.doOwner(ctx, prefix, func(p *proxy, s3client pb.S3ProxyClient) (err error) {
+			rsp, err = s3client.RemoveFiles(ctx, req)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		reply.RemovedFiles += rsp.RemovedFiles
+		reply.RemovedBytes += rsp.RemovedBytes
+		reply.Kept = append(reply.Kept, rsp.Kept...)
+	}
+	return reply, nil
+}
+
 func (c *Client) PutFile(ctx context.Context, key string, buf []byte) error {
-	_, err := c.s3client.PutFile(ctx, &pb.PutFileRequest{
-		Path: key,
-		Data: buf,
+	return c.do(ctx, key, func(p *proxy, s3client pb.S3ProxyClient) error {
+		_, err := s3client.PutFile(ctx, &pb.PutFileRequest{
+			Path: key,
+			Data: buf,
+		})
+		return err
 	})
-	return err
 }
 
-func (c *Client) GetFile(ctx context.Context, key string) ([]byte, error) {
-	rsp, err := c.s3client.GetFile(ctx, &pb.GetFileRequest{
-		Path: key,
+func (c *Client) GetFile(ctx context.Context, key string) (buf []byte, err error) {
+	err = c.do(ctx, key, func(p *proxy, s3client pb.S3ProxyClient) error {
+		rsp, err := s3client.GetFile(ctx, &pb.GetFileRequest{
+			Path: key,
+		})
+		if err != nil {
+			return err
+		}
+		buf = rsp.Data
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return rsp.Data, nil
+	return buf, nil
+}
+
+// PutCompressedFile compresses buf with the codec of the client and stores it
+// at key, it returns the chunk of the stored object with its checksum and
+// codec.
+func (c *Client) PutCompressedFile(ctx context.Context, key string, buf []byte) (chunk *pb.SnapshotChunk, err error) {
+	codec := c.compression
+	if codec == pb.BlockInfo_NONE {
+		codec = pb.BlockInfo_UNCOMPRESSED
+	}
+	data, err := Compress(codec, buf)
+	if err != nil {
+		return nil, err
+	}
+	err = c.PutFile(ctx, key, data)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SnapshotChunk{Key: key, Checksum: Checksum(data), Compression: codec}, nil
+}
+
+// GetCompressedFile returns the decompressed file of chunk stored by
+// PutCompressedFile, the object is checked against the checksum of chunk if
+// it is not empty.
+func (c *Client) GetCompressedFile(ctx context.Context, chunk *pb.SnapshotChunk) (buf []byte, err error) {
+	data, err := c.GetFile(ctx, chunk.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunk.Checksum) > 0 && !bytes.Equal(chunk.Checksum, Checksum(data)) {
+		c.s3Metric.IncreaseChecksumMismatch("s3-proxy")
+		utils.Logger().Error("GetCompressedFile checksum mismatch", zap.String("key", chunk.Key), zap.Int("size", len(data)))
+		return nil, utils.ErrChecksumMismatch
+	}
+	return Decompress(chunk.Compression, data)
 }
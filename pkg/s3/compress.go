@@ -0,0 +1,110 @@
+package s3
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	zstdMagic   = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	snappyMagic = []byte("\xff\x06\x00\x00sNaPpY")
+
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// ParseCompression parses a codec name such as "zstd", an empty name or
+// "none" means no compression.
+func ParseCompression(name string) (pb.BlockInfo_Compression, error) {
+	if name == "" || strings.EqualFold(name, "none") {
+		return pb.BlockInfo_UNCOMPRESSED, nil
+	}
+	codec, ok := pb.BlockInfo_Compression_value[strings.ToUpper(name)]
+	if !ok {
+		return pb.BlockInfo_NONE, fmt.Errorf("unknown compression: %s", name)
+	}
+	return pb.BlockInfo_Compression(codec), nil
+}
+
+// Compress compresses data with codec, both zstd and snappy use their framed
+// formats so the codec of objects put before codecs were recorded can be
+// recognized from the data itself.
+func Compress(codec pb.BlockInfo_Compression, data []byte) ([]byte, error) {
+	switch codec {
+	case pb.BlockInfo_NONE, pb.BlockInfo_UNCOMPRESSED:
+		return data, nil
+	case pb.BlockInfo_ZSTD:
+		return zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/2)), nil
+	case pb.BlockInfo_SNAPPY:
+		buf := bytes.NewBuffer(make([]byte, 0, len(data)/2))
+		w := snappy.NewBufferedWriter(buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown compression: %v", codec)
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// NewCompressWriter returns a writer compressing to w with codec in the
+// formats of Compress, it must be closed to flush the compressed data.
+func NewCompressWriter(codec pb.BlockInfo_Compression, w io.Writer) (io.WriteCloser, error) {
+	switch codec {
+	case pb.BlockInfo_NONE, pb.BlockInfo_UNCOMPRESSED:
+		return nopCloser{w}, nil
+	case pb.BlockInfo_ZSTD:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	case pb.BlockInfo_SNAPPY:
+		return snappy.NewBufferedWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unknown compression: %v", codec)
+	}
+}
+
+// DetectCompression returns the codec data was compressed with. Marshalled
+// pb.Block never starts with a zstd or snappy frame header, so blocks written
+// before compression was introduced are detected as NONE.
+func DetectCompression(data []byte) pb.BlockInfo_Compression {
+	switch {
+	case bytes.HasPrefix(data, zstdMagic):
+		return pb.BlockInfo_ZSTD
+	case bytes.HasPrefix(data, snappyMagic):
+		return pb.BlockInfo_SNAPPY
+	default:
+		return pb.BlockInfo_NONE
+	}
+}
+
+// Decompress reverses Compress with codec, the codec of data is detected if
+// it is NONE. Uncompressed data is returned as-is.
+func Decompress(codec pb.BlockInfo_Compression, data []byte) ([]byte, error) {
+	if codec == pb.BlockInfo_NONE {
+		codec = DetectCompression(data)
+	}
+	switch codec {
+	case pb.BlockInfo_NONE, pb.BlockInfo_UNCOMPRESSED:
+		return data, nil
+	case pb.BlockInfo_ZSTD:
+		return zstdDecoder.DecodeAll(data, nil)
+	case pb.BlockInfo_SNAPPY:
+		return io.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+	default:
+		return nil, fmt.Errorf("unknown compression: %v", codec)
+	}
+}
@@ -0,0 +1,190 @@
+package s3
+
+import (
+	"container/list"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const diskCacheName = "s3-proxy-disk"
+
+// diskCache keeps blocks in a local directory below maxBytes, evicting the
+// least recently used ones. The recency of a block is its file modification
+// time, so the cache is reloaded in order after a restart. Blocks are sealed
+// by keyring if it is not nil.
+type diskCache struct {
+	sync.Mutex
+	store    *FsStore
+	keyring  *Keyring
+	maxBytes int64
+	bytes    int64
+	recency  *list.List // of *diskEntry, the most recently used first
+	entries  map[string]*list.Element
+	metrics  *metrics.CacheMetrics
+}
+
+type diskEntry struct {
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+// newDiskCache opens the cache at dir, loading the blocks already in it.
+func newDiskCache(dir string, maxBytes int64, keyring *Keyring) (*diskCache, error) {
+	store, err := NewFsStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	var loaded []*diskEntry
+	err = filepath.WalkDir(store.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			// left over by a crash during a put.
+			return os.Remove(p)
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(store.root, p)
+		if err != nil {
+			return err
+		}
+		loaded = append(loaded, &diskEntry{key: filepath.ToSlash(rel), size: fi.Size(), modTime: fi.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].modTime.Before(loaded[j].modTime) })
+	c := &diskCache{
+		store:    store,
+		keyring:  keyring,
+		maxBytes: maxBytes,
+		recency:  list.New(),
+		entries:  make(map[string]*list.Element),
+		metrics:  metrics.NewCacheMetrics(),
+	}
+	c.Lock()
+	defer c.Unlock()
+	for _, entry := range loaded {
+		c.entries[entry.key] = c.recency.PushFront(entry)
+		c.bytes += entry.size
+	}
+	return c, c.evict(context.Background())
+}
+
+// evict removes the least recently used blocks until the cache fits its
+// budget, c must be locked.
+func (c *diskCache) evict(ctx context.Context) error {
+	for c.maxBytes > 0 && c.bytes > c.maxBytes {
+		elem := c.recency.Back()
+		if elem == nil {
+			break
+		}
+		if err := c.drop(ctx, elem.Value.(*diskEntry).key); err != nil {
+			return err
+		}
+		c.metrics.IncreaseEvictions(diskCacheName, "bytes")
+	}
+	c.metrics.SetBytes(diskCacheName, c.bytes)
+	return nil
+}
+
+// drop removes the block at key, c must be locked.
+func (c *diskCache) drop(ctx context.Context, key string) error {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	delete(c.entries, key)
+	c.recency.Remove(elem)
+	c.bytes -= elem.Value.(*diskEntry).size
+	return c.store.Delete(ctx, key)
+}
+
+// get returns the block at key, nil if it is not cached.
+func (c *diskCache) get(ctx context.Context, key string) ([]byte, error) {
+	c.Lock()
+	elem, ok := c.entries[key]
+	if ok {
+		c.recency.MoveToFront(elem)
+	}
+	c.Unlock()
+	if !ok {
+		c.metrics.IncreaseMisses(diskCacheName)
+		return nil, nil
+	}
+	buf, err := c.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if buf == nil {
+		// evicted meanwhile or removed behind our back.
+		c.metrics.IncreaseMisses(diskCacheName)
+		return nil, c.remove(ctx, key)
+	}
+	if c.keyring != nil {
+		if buf, err = c.keyring.open(buf); err != nil {
+			// e.g. sealed by a key rotated out of the keyring, the block is
+			// read from the store again.
+			utils.Logger().Warn("disk cache open error", zap.String("key", key), zap.Error(err))
+			c.metrics.IncreaseMisses(diskCacheName)
+			return nil, c.remove(ctx, key)
+		}
+	}
+	c.metrics.IncreaseHits(diskCacheName)
+	p, err := c.store.keyPath(key)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := os.Chtimes(p, now, now); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// put caches the block at key.
+func (c *diskCache) put(ctx context.Context, key string, data []byte) (err error) {
+	if c.keyring != nil {
+		if data, err = c.keyring.seal(data); err != nil {
+			return err
+		}
+	}
+	if err = c.store.Put(ctx, key, data); err != nil {
+		return err
+	}
+	c.Lock()
+	defer c.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.recency.Remove(elem)
+		c.bytes -= elem.Value.(*diskEntry).size
+	}
+	c.entries[key] = c.recency.PushFront(&diskEntry{key: key, size: int64(len(data)), modTime: time.Now()})
+	c.bytes += int64(len(data))
+	return c.evict(ctx)
+}
+
+// remove drops the block at key from the cache.
+func (c *diskCache) remove(ctx context.Context, key string) error {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.drop(ctx, key); err != nil {
+		return err
+	}
+	c.metrics.SetBytes(diskCacheName, c.bytes)
+	return nil
+}
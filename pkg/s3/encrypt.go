@@ -0,0 +1,492 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// encryptSegmentSize is the plaintext size of a sealed segment of an
+	// object, a range of an object is read by its segments.
+	encryptSegmentSize = 64 << 10
+	// encryptHeaderRead is read at once to parse the header of an object.
+	encryptHeaderRead = 1 << 10
+	maxKeyIdLen       = 255
+	dataKeySize       = 32
+)
+
+// encryptMagic leads encrypted objects, marshalled protos and compressed
+// frames never start with a zero byte so objects stored in plaintext are told
+// apart.
+var encryptMagic = []byte("\x00ndxenc1")
+
+// Keyring holds the master keys sealing the data keys of encrypted objects.
+// New objects are sealed by the primary key, the other keys are kept to open
+// the objects sealed before a rotation.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// keyringFile is the json of a keyring file, its keys are base64 AES keys by
+// id, e.g. {"primary": "2024-01", "keys": {"2024-01": "..."}}.
+type keyringFile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// LoadKeyring reads a keyring file.
+func LoadKeyring(path string) (*Keyring, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &keyringFile{}
+	if err := json.Unmarshal(buf, file); err != nil {
+		return nil, fmt.Errorf("keyring %s: %w", path, err)
+	}
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, key := range file.Keys {
+		keys[id], err = base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("keyring %s, key %s: %w", path, id, err)
+		}
+	}
+	return NewKeyring(file.Primary, keys)
+}
+
+// NewKeyring creates a Keyring of AES keys by id sealing new objects by the
+// key primary.
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > maxKeyIdLen {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q not in keyring", primary)
+	}
+	return k, nil
+}
+
+// Primary returns the id of the key sealing new objects.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealKey seals dataKey by the primary key into the header of an object.
+func (k *Keyring) sealKey(dataKey []byte) ([]byte, error) {
+	master := k.keys[k.primary]
+	nonce := make([]byte, master.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	header, err := proto.Marshal(&pb.EncryptionHeader{
+		KeyId:       k.primary,
+		SealedKey:   master.Seal(nonce, nonce, dataKey, []byte(k.primary)),
+		SegmentSize: encryptSegmentSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, len(encryptMagic)+4, len(encryptMagic)+4+len(header))
+	copy(buf, encryptMagic)
+	binary.BigEndian.PutUint32(buf[len(encryptMagic):], uint32(len(header)))
+	return append(buf, header...), nil
+}
+
+// newObject returns the header and the cipher of a new object.
+func (k *Keyring) newObject() (header []byte, aead cipher.AEAD, err error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+	header, err = k.sealKey(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err = newAEAD(dataKey)
+	return header, aead, err
+}
+
+func isEncrypted(buf []byte) bool {
+	return bytes.HasPrefix(buf, encryptMagic)
+}
+
+// openHeader parses the header at the start of an encrypted object, it
+// returns the data key, the header and its length in buf.
+func (k *Keyring) openHeader(buf []byte) (dataKey []byte, header *pb.EncryptionHeader, n int, err error) {
+	n = len(encryptMagic) + 4
+	if len(buf) < n {
+		return nil, nil, 0, utils.ErrDecryptFailed
+	}
+	size := int(binary.BigEndian.Uint32(buf[len(encryptMagic):]))
+	if len(buf) < n+size {
+		return nil, nil, 0, utils.ErrDecryptFailed
+	}
+	header = &pb.EncryptionHeader{}
+	if err := proto.Unmarshal(buf[n:n+size], header); err != nil {
+		return nil, nil, 0, err
+	}
+	n += size
+	if header.SegmentSize <= 0 {
+		return nil, nil, 0, utils.ErrDecryptFailed
+	}
+	master, ok := k.keys[header.KeyId]
+	if !ok {
+		return nil, nil, 0, fmt.Errorf("%w: %s", utils.ErrUnknownKey, header.KeyId)
+	}
+	if len(header.SealedKey) < master.NonceSize() {
+		return nil, nil, 0, utils.ErrDecryptFailed
+	}
+	nonce := header.SealedKey[:master.NonceSize()]
+	dataKey, err = master.Open(nil, nonce, header.SealedKey[master.NonceSize():], []byte(header.KeyId))
+	if err != nil {
+		return nil, nil, 0, utils.ErrDecryptFailed
+	}
+	return dataKey, header, n, nil
+}
+
+// segmentNonce is the nonce of the segment index of an object, the data key
+// of an object is never reused so neither is a nonce.
+func segmentNonce(aead cipher.AEAD, index int64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], uint64(index))
+	return nonce
+}
+
+// segmentAD authenticates whether a segment is the last of its object, so a
+// truncated object does not open.
+func segmentAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// seal encrypts data into a new object.
+func (k *Keyring) seal(data []byte) ([]byte, error) {
+	header, aead, err := k.newObject()
+	if err != nil {
+		return nil, err
+	}
+	segments := (len(data) + encryptSegmentSize - 1) / encryptSegmentSize
+	if segments == 0 {
+		// an empty object has an empty last segment.
+		segments = 1
+	}
+	buf := make([]byte, 0, len(header)+len(data)+segments*aead.Overhead())
+	buf = append(buf, header...)
+	for i := 0; i < segments; i++ {
+		end := (i + 1) * encryptSegmentSize
+		if end > len(data) {
+			end = len(data)
+		}
+		final := i == segments-1
+		buf = aead.Seal(buf, segmentNonce(aead, int64(i)), data[i*encryptSegmentSize:end], segmentAD(final))
+	}
+	return buf, nil
+}
+
+// openSegments decrypts the sealed segments of buf starting at segment first,
+// toEnd tells buf ends with the last segment of its object.
+func openSegments(aead cipher.AEAD, buf []byte, segmentSize int, first int64, toEnd bool) ([]byte, error) {
+	sealedSize := segmentSize + aead.Overhead()
+	var data []byte
+	for i := first; len(buf) > 0; i++ {
+		n := sealedSize
+		if n > len(buf) {
+			n = len(buf)
+		}
+		last := n == len(buf)
+		var err error
+		var plain []byte
+		if last && !toEnd && n == sealedSize {
+			// a full segment ending a range may or may not end its object.
+			plain, err = aead.Open(data, segmentNonce(aead, i), buf[:n], segmentAD(false))
+			if err != nil {
+				plain, err = aead.Open(data, segmentNonce(aead, i), buf[:n], segmentAD(true))
+			}
+		} else {
+			plain, err = aead.Open(data, segmentNonce(aead, i), buf[:n], segmentAD(last))
+		}
+		if err != nil {
+			return nil, utils.ErrDecryptFailed
+		}
+		data = plain
+		buf = buf[n:]
+	}
+	return data, nil
+}
+
+// open decrypts an object, objects stored in plaintext are returned as is.
+func (k *Keyring) open(buf []byte) ([]byte, error) {
+	if !isEncrypted(buf) {
+		return buf, nil
+	}
+	dataKey, header, n, err := k.openHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == n {
+		// an object always has a last segment.
+		return nil, utils.ErrDecryptFailed
+	}
+	data, err := openSegments(aead, buf[n:], int(header.SegmentSize), 0, true)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+// reseal seals the data key of an encrypted object by the primary key, the
+// segments of the object are kept as is.
+func (k *Keyring) reseal(buf []byte) ([]byte, error) {
+	dataKey, _, n, err := k.openHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	header, err := k.sealKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, buf[n:]...), nil
+}
+
+// EncryptedStore encrypts the objects of a BlobStore at rest by envelope
+// encryption: each object is sealed by AES-GCM with its own data key, sealed
+// in turn by a master key of a Keyring whose id is kept in the object header.
+// Objects stored before encryption was enabled are read as is.
+type EncryptedStore struct {
+	BlobStore
+	keyring *Keyring
+}
+
+func NewEncryptedStore(store BlobStore, keyring *Keyring) *EncryptedStore {
+	return &EncryptedStore{BlobStore: store, keyring: keyring}
+}
+
+func (s *EncryptedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	buf, err := s.BlobStore.Get(ctx, key)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	data, err := s.keyring.open(buf)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// GetRange reads the header of the object then the segments of the range.
+func (s *EncryptedStore) GetRange(ctx context.Context, key string, offset, length int64) ([]byte, error) {
+	head, err := s.BlobStore.GetRange(ctx, key, 0, encryptHeaderRead)
+	if err != nil || head == nil {
+		return nil, err
+	}
+	if !isEncrypted(head) {
+		return s.BlobStore.GetRange(ctx, key, offset, length)
+	}
+	dataKey, header, n, err := s.keyring.openHeader(head)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", key, err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	segmentSize := int64(header.SegmentSize)
+	sealedSize := segmentSize + int64(aead.Overhead())
+	first := offset / segmentSize
+	var sealedLength int64
+	if length > 0 {
+		sealedLength = ((offset+length-1)/segmentSize - first + 1) * sealedSize
+	}
+	buf, err := s.BlobStore.GetRange(ctx, key, int64(n)+first*sealedSize, sealedLength)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	toEnd := length == 0 || int64(len(buf)) < sealedLength
+	data, err := openSegments(aead, buf, int(segmentSize), first, toEnd)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", key, err)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return sliceRange(data, offset-first*segmentSize, length), nil
+}
+
+func (s *EncryptedStore) Put(ctx context.Context, key string, data []byte) error {
+	buf, err := s.keyring.seal(data)
+	if err != nil {
+		return err
+	}
+	return s.BlobStore.Put(ctx, key, buf)
+}
+
+func (s *EncryptedStore) NewWriter(ctx context.Context, key string) (ObjectWriter, error) {
+	header, aead, err := s.keyring.newObject()
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.BlobStore.NewWriter(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, buf: make([]byte, 0, encryptSegmentSize)}, nil
+}
+
+// Rekey seals the data keys of the objects under prefix by the primary key
+// and encrypts the objects stored in plaintext, it returns the number of
+// rewritten objects. An object changed while it is rekeyed is skipped. The
+// files of the header index are removed instead, rewriting one could put back
+// a stale copy, and the proxies seal them again from the headers.
+func (s *EncryptedStore) Rekey(ctx context.Context, prefix string) (n int, err error) {
+	startAfter := ""
+	for {
+		keys, err := s.BlobStore.List(ctx, prefix, startAfter, ListPageSize)
+		if err != nil {
+			return n, err
+		}
+		for _, key := range keys {
+			rewritten, err := s.rekey(ctx, key)
+			if err != nil {
+				return n, fmt.Errorf("rekey %s: %w", key, err)
+			}
+			if rewritten {
+				n++
+			}
+		}
+		if len(keys) < ListPageSize {
+			return n, nil
+		}
+		startAfter = keys[len(keys)-1]
+	}
+}
+
+func (s *EncryptedStore) rekey(ctx context.Context, key string) (bool, error) {
+	stat, err := s.BlobStore.Stat(ctx, key)
+	if err != nil || stat == nil {
+		return false, err
+	}
+	buf, err := s.BlobStore.Get(ctx, key)
+	if err != nil || buf == nil {
+		return false, err
+	}
+	if isEncrypted(buf) {
+		_, header, _, err := s.keyring.openHeader(buf)
+		if err != nil {
+			return false, err
+		}
+		if header.KeyId == s.keyring.primary {
+			return false, nil
+		}
+	}
+	if utils.IsHeaderIndexKey(key) {
+		return true, s.BlobStore.Delete(ctx, key)
+	}
+	var out []byte
+	if isEncrypted(buf) {
+		out, err = s.keyring.reseal(buf)
+	} else {
+		out, err = s.keyring.seal(buf)
+	}
+	if err != nil {
+		return false, err
+	}
+	latest, err := s.BlobStore.Stat(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if latest == nil || latest.Size != stat.Size || !latest.ModTime.Equal(stat.ModTime) {
+		utils.Logger().Warn("rekey skipped a changed object", zap.String("key", key))
+		return false, nil
+	}
+	return true, s.BlobStore.Put(ctx, key, out)
+}
+
+// encryptWriter seals the segments of an object as they are written, the
+// last one is only sealed on Close.
+type encryptWriter struct {
+	w     ObjectWriter
+	aead  cipher.AEAD
+	buf   []byte
+	out   []byte
+	index int64
+}
+
+func (e *encryptWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if len(e.buf) == encryptSegmentSize {
+			if err := e.seal(false); err != nil {
+				return n, err
+			}
+		}
+		m := encryptSegmentSize - len(e.buf)
+		if m > len(p) {
+			m = len(p)
+		}
+		e.buf = append(e.buf, p[:m]...)
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) seal(final bool) error {
+	e.out = e.aead.Seal(e.out[:0], segmentNonce(e.aead, e.index), e.buf, segmentAD(final))
+	e.index++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(e.out)
+	return err
+}
+
+func (e *encryptWriter) Close() error {
+	if err := e.seal(true); err != nil {
+		return err
+	}
+	return e.w.Close()
+}
+
+func (e *encryptWriter) Abort() error {
+	return e.w.Abort()
+}
@@ -0,0 +1,188 @@
+package s3
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
+)
+
+const (
+	// hedgeSamples is the number of recent read latencies hedging is based on.
+	hedgeSamples = 1000
+	// minHedgeSamples is the number of reads before any read is hedged.
+	minHedgeSamples = 100
+	// minHedgeDelay keeps fast stores from being hedged on every jitter.
+	minHedgeDelay = 10 * time.Millisecond
+)
+
+// MaxS3Reads is the max concurrent reads of the store by the proxy, more
+// reads wait in a queue, 0 disables the limit.
+var MaxS3Reads = 64
+
+// HedgePercentile is the percentile of the recent read latencies of a kind
+// after which a read is hedged by a second one, 0 disables hedging.
+var HedgePercentile = 0.95
+
+// storeReader bounds the concurrent reads of a store and hedges the slow ones,
+// a hedge is only sent if it does not have to wait in the queue. Reads are
+// hedged against the recent reads of their kind, e.g. of the same block type,
+// so small header reads do not get large data reads hedged.
+type storeReader struct {
+	sync.Mutex
+	store     BlobStore
+	slots     chan struct{} // nil if unbounded
+	latencies map[string]*latencyWindow
+	metrics   *metrics.S3Metrics
+}
+
+func newStoreReader(store BlobStore) *storeReader {
+	r := &storeReader{
+		store:     store,
+		latencies: make(map[string]*latencyWindow),
+		metrics:   metrics.NewS3Metrics(),
+	}
+	if MaxS3Reads > 0 {
+		r.slots = make(chan struct{}, MaxS3Reads)
+	}
+	return r
+}
+
+func (r *storeReader) acquire(ctx context.Context) error {
+	if r.slots == nil {
+		return nil
+	}
+	start := time.Now()
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	r.metrics.ObserveQueueWait(r.store.Name(), float64(time.Since(start).Milliseconds()))
+	return nil
+}
+
+func (r *storeReader) tryAcquire() bool {
+	if r.slots == nil {
+		return true
+	}
+	select {
+	case r.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *storeReader) release() {
+	if r.slots != nil {
+		<-r.slots
+	}
+}
+
+func (r *storeReader) latency(kind string) *latencyWindow {
+	r.Lock()
+	defer r.Unlock()
+	w, ok := r.latencies[kind]
+	if !ok {
+		w = &latencyWindow{}
+		r.latencies[kind] = w
+	}
+	return w
+}
+
+// read runs get once a slot is free, hedging it if it is slower than
+// HedgePercentile of the recent reads of kind. The first successful result
+// wins and the other read is canceled.
+func (r *storeReader) read(ctx context.Context, kind string, get func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if err := r.acquire(ctx); err != nil {
+		return nil, err
+	}
+	latency := r.latency(kind)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		buf []byte
+		err error
+	}
+	results := make(chan result, 2)
+	run := func() {
+		defer r.release()
+		buf, err := get(ctx)
+		results <- result{buf: buf, err: err}
+	}
+	start := time.Now()
+	go run()
+	pending := 1
+	var hedge <-chan time.Time
+	if delay, ok := latency.threshold(); ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+	for {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				latency.record(time.Since(start))
+				return res.buf, nil
+			}
+			if pending == 0 {
+				return nil, res.err
+			}
+		case <-hedge:
+			hedge = nil
+			if r.tryAcquire() {
+				r.metrics.IncreaseHedges(r.store.Name())
+				pending++
+				go run()
+			}
+		}
+	}
+}
+
+// latencyWindow keeps the latencies of the recent reads.
+type latencyWindow struct {
+	sync.Mutex
+	samples []time.Duration
+	next    int
+	records int
+	hedge   time.Duration
+}
+
+func (w *latencyWindow) record(d time.Duration) {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.samples) < hedgeSamples {
+		w.samples = append(w.samples, d)
+	} else {
+		w.samples[w.next] = d
+		w.next = (w.next + 1) % hedgeSamples
+	}
+	w.records++
+	// the percentile is recomputed every tenth of the window.
+	if w.records%(hedgeSamples/10) != 0 || len(w.samples) < minHedgeSamples || HedgePercentile <= 0 || HedgePercentile > 1 {
+		return
+	}
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	w.hedge = sorted[int(HedgePercentile*float64(len(sorted)-1))]
+	if w.hedge < minHedgeDelay {
+		w.hedge = minHedgeDelay
+	}
+}
+
+// threshold returns the delay after which a read is hedged, false while there
+// are too few samples or hedging is disabled.
+func (w *latencyWindow) threshold() (time.Duration, bool) {
+	if HedgePercentile <= 0 || HedgePercentile > 1 {
+		return 0, false
+	}
+	w.Lock()
+	defer w.Unlock()
+	return w.hedge, w.hedge > 0
+}
@@ -0,0 +1,415 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// IndexSegmentSize is the number of heights of a header index segment,
+	// the open segment is listed from its headers by the proxies not writing
+	// the chain so it is kept small.
+	IndexSegmentSize = 1000
+)
+
+// headerIndex maps the heights of the headers of a chain to their msg
+// offsets and hashes in segment files, listing headers by height then reads
+// a few files instead of LISTing the header prefix. The headers themselves
+// are the entries of the open segment, the highest one whose start is kept in
+// a head file. A segment is sealed into its file from a LIST of its headers
+// once a header above it is put, and sealed again for a late header or a
+// removal, so entries are never lost to a stale copy of a segment. The proxy
+// owning a chain is its single writer and keeps the open segment in memory,
+// the listings of other proxies LIST it. Headers put through another proxy
+// are missed by the listings of the writer until their segment is sealed,
+// the writer reloads the open segment once another one moved the head.
+type headerIndex struct {
+	sync.Mutex // guards topics
+	store      BlobStore
+	topics     map[string]*topicIndex
+}
+
+// topicIndex is the index of a chain, its lock serializes the writes of the
+// chain.
+type topicIndex struct {
+	sync.Mutex
+	head int64 // start of the open segment, -1 if unknown
+	// open is the open segment once the chain is written through this proxy,
+	// it is copied on write as list iterates it unlocked.
+	open *pb.HeaderIndex
+}
+
+func newHeaderIndex(store BlobStore) *headerIndex {
+	return &headerIndex{
+		store:  store,
+		topics: make(map[string]*topicIndex),
+	}
+}
+
+func segmentStart(blockNum int64) int64 {
+	if blockNum < 0 {
+		return 0
+	}
+	return blockNum - blockNum%IndexSegmentSize
+}
+
+func entryLess(a, b *pb.HeaderIndexEntry) bool {
+	if a.BlockNum != b.BlockNum {
+		return a.BlockNum < b.BlockNum
+	}
+	if a.MsgOffset != b.MsgOffset {
+		return a.MsgOffset < b.MsgOffset
+	}
+	return a.BlockHash < b.BlockHash
+}
+
+func (x *headerIndex) topic(env, chainId, role string) *topicIndex {
+	prefix := utils.TopicPrefix(env, chainId, role)
+	x.Lock()
+	defer x.Unlock()
+	t, ok := x.topics[prefix]
+	if !ok {
+		t = &topicIndex{head: -1}
+		x.topics[prefix] = t
+	}
+	return t
+}
+
+// load returns the segment file at start, nil if it does not exist.
+func (x *headerIndex) load(ctx context.Context, env, chainId, role string, start int64) (*pb.HeaderIndex, error) {
+	buf, err := x.store.Get(ctx, utils.HeaderIndexKey(env, chainId, role, start))
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	index := &pb.HeaderIndex{}
+	if err := proto.Unmarshal(buf, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func (x *headerIndex) save(ctx context.Context, env, chainId, role string, start int64, index *pb.HeaderIndex) error {
+	key := utils.HeaderIndexKey(env, chainId, role, start)
+	if len(index.Entries) == 0 {
+		return x.store.Delete(ctx, key)
+	}
+	buf, err := proto.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return x.store.Put(ctx, key, buf)
+}
+
+// head returns the start of the open segment of the chain, -1 if the chain
+// has no head file.
+func (x *headerIndex) head(ctx context.Context, env, chainId, role string) (int64, error) {
+	buf, err := x.store.Get(ctx, utils.HeaderIndexHeadKey(env, chainId, role))
+	if err != nil || buf == nil {
+		return -1, err
+	}
+	return strconv.ParseInt(string(buf), 10, 64)
+}
+
+func (x *headerIndex) setHead(ctx context.Context, env, chainId, role string, head int64) error {
+	return x.store.Put(ctx, utils.HeaderIndexHeadKey(env, chainId, role), []byte(strconv.FormatInt(head, 10)))
+}
+
+// scan lists the headers from height start below end. If there are none it
+// returns the height of the next header, -1 if there is no later header.
+func (x *headerIndex) scan(ctx context.Context, env, chainId, role string, start, end int64) (index *pb.HeaderIndex, next int64, err error) {
+	headerPrefix := utils.CommonPrefix(env, chainId, role, pb.BlockInfo_HEADER)
+	startAfter := fmt.Sprintf("%s/%012d", headerPrefix, start)
+	index = &pb.HeaderIndex{}
+	for {
+		keys, err := x.store.List(ctx, headerPrefix, startAfter, ListPageSize)
+		if err != nil {
+			return nil, -1, err
+		}
+		for _, key := range keys {
+			info, err := utils.PrefixToHeaderInfo(key)
+			if err != nil {
+				return nil, -1, err
+			}
+			if info.BlockNum >= end {
+				if len(index.Entries) == 0 {
+					return index, info.BlockNum, nil
+				}
+				return index, -1, nil
+			}
+			index.Entries = append(index.Entries, &pb.HeaderIndexEntry{
+				BlockNum:  info.BlockNum,
+				MsgOffset: info.MsgOffset,
+				BlockHash: info.BlockHash,
+			})
+		}
+		if len(keys) < ListPageSize {
+			return index, -1, nil
+		}
+		startAfter = keys[len(keys)-1]
+	}
+}
+
+// seal writes the segment file at start from its headers, as scan.
+func (x *headerIndex) seal(ctx context.Context, env, chainId, role string, start int64) (*pb.HeaderIndex, int64, error) {
+	index, next, err := x.scan(ctx, env, chainId, role, start, start+IndexSegmentSize)
+	if err != nil {
+		return nil, -1, err
+	}
+	return index, next, x.save(ctx, env, chainId, role, start, index)
+}
+
+// open loads the open segment of the chain of t, which must be locked.
+func (x *headerIndex) open(ctx context.Context, t *topicIndex, env, chainId, role string, start int64) error {
+	head, err := x.head(ctx, env, chainId, role)
+	if err != nil {
+		return err
+	}
+	if head < 0 {
+		// a chain is indexed from the segment of its first header put
+		// through a proxy, the segments below are sealed as they are listed.
+		head = start
+		if err := x.setHead(ctx, env, chainId, role, head); err != nil {
+			return err
+		}
+	}
+	open, _, err := x.scan(ctx, env, chainId, role, head, head+IndexSegmentSize)
+	if err != nil {
+		return err
+	}
+	t.head, t.open = head, open
+	return nil
+}
+
+// add indexes the header info, it must be stored already.
+func (x *headerIndex) add(ctx context.Context, info *pb.BlockInfo) error {
+	t := x.topic(info.Env, info.ChainId, info.Role)
+	t.Lock()
+	defer t.Unlock()
+	start := segmentStart(info.BlockNum)
+	if t.open != nil {
+		// the chain was written through another proxy if its head moved.
+		head, err := x.head(ctx, info.Env, info.ChainId, info.Role)
+		if err != nil {
+			return err
+		}
+		if head != t.head {
+			t.open = nil
+		}
+	}
+	if t.open == nil {
+		if err := x.open(ctx, t, info.Env, info.ChainId, info.Role, start); err != nil {
+			return err
+		}
+	}
+	if start < t.head {
+		_, _, err := x.seal(ctx, info.Env, info.ChainId, info.Role, start)
+		return err
+	}
+	if start == t.head {
+		entry := &pb.HeaderIndexEntry{
+			BlockNum:  info.BlockNum,
+			MsgOffset: info.MsgOffset,
+			BlockHash: info.BlockHash,
+		}
+		entries := t.open.Entries
+		i := sort.Search(len(entries), func(i int) bool { return !entryLess(entries[i], entry) })
+		if i < len(entries) && proto.Equal(entries[i], entry) {
+			return nil
+		}
+		open := make([]*pb.HeaderIndexEntry, 0, len(entries)+1)
+		open = append(open, entries[:i]...)
+		open = append(open, entry)
+		open = append(open, entries[i:]...)
+		t.open = &pb.HeaderIndex{Entries: open}
+		return nil
+	}
+	// the first header above the open segment seals the segments below its
+	// own, which is opened.
+	index, _, err := x.scan(ctx, info.Env, info.ChainId, info.Role, t.head, start+IndexSegmentSize)
+	if err != nil {
+		return err
+	}
+	open := &pb.HeaderIndex{}
+	var sealed []*pb.HeaderIndex
+	var starts []int64
+	for _, entry := range index.Entries {
+		segment := segmentStart(entry.BlockNum)
+		if segment == start {
+			open.Entries = append(open.Entries, entry)
+			continue
+		}
+		if len(starts) == 0 || starts[len(starts)-1] != segment {
+			starts = append(starts, segment)
+			sealed = append(sealed, &pb.HeaderIndex{})
+		}
+		sealed[len(sealed)-1].Entries = append(sealed[len(sealed)-1].Entries, entry)
+	}
+	for i, segment := range starts {
+		if err := x.save(ctx, info.Env, info.ChainId, info.Role, segment, sealed[i]); err != nil {
+			return err
+		}
+	}
+	if err := x.setHead(ctx, info.Env, info.ChainId, info.Role, start); err != nil {
+		return err
+	}
+	t.head, t.open = start, open
+	return nil
+}
+
+// remove drops the header infos from the index, they must be removed from
+// the store already.
+func (x *headerIndex) remove(ctx context.Context, infos []*pb.BlockInfo) error {
+	var prefixes []string
+	chains := make(map[string][]*pb.BlockInfo)
+	for _, info := range infos {
+		prefix := utils.TopicPrefix(info.Env, info.ChainId, info.Role)
+		if _, ok := chains[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+		chains[prefix] = append(chains[prefix], info)
+	}
+	for _, prefix := range prefixes {
+		if err := x.removeChain(ctx, chains[prefix]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeChain drops the header infos of a chain from the index.
+func (x *headerIndex) removeChain(ctx context.Context, infos []*pb.BlockInfo) error {
+	env, chainId, role := infos[0].Env, infos[0].ChainId, infos[0].Role
+	t := x.topic(env, chainId, role)
+	t.Lock()
+	defer t.Unlock()
+	head := t.head
+	if t.open == nil {
+		var err error
+		head, err = x.head(ctx, env, chainId, role)
+		if err != nil {
+			return err
+		}
+	}
+	var sealed []int64
+	seen := make(map[int64]bool)
+	for _, info := range infos {
+		start := segmentStart(info.BlockNum)
+		if start < head {
+			if !seen[start] {
+				seen[start] = true
+				sealed = append(sealed, start)
+			}
+			continue
+		}
+		if start != head || t.open == nil {
+			continue
+		}
+		entries := make([]*pb.HeaderIndexEntry, 0, len(t.open.Entries))
+		for _, entry := range t.open.Entries {
+			if entry.BlockNum != info.BlockNum || entry.MsgOffset != info.MsgOffset || entry.BlockHash != info.BlockHash {
+				entries = append(entries, entry)
+			}
+		}
+		t.open = &pb.HeaderIndex{Entries: entries}
+	}
+	for _, start := range sealed {
+		if _, _, err := x.seal(ctx, env, chainId, role, start); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list calls fn with the headers from height start in key order until fn
+// returns false.
+func (x *headerIndex) list(ctx context.Context, env, chainId, role string, start int64, fn func(info *pb.BlockInfo) bool) error {
+	t := x.topic(env, chainId, role)
+	segment := segmentStart(start)
+	for {
+		t.Lock()
+		head, open := t.head, t.open
+		t.Unlock()
+		var err error
+		if open == nil && segment >= head {
+			// the head of a chain written through another proxy moves.
+			head, err = x.head(ctx, env, chainId, role)
+			if err != nil {
+				return err
+			}
+			t.Lock()
+			if t.open == nil {
+				t.head = head
+			}
+			t.Unlock()
+		}
+		if head >= 0 && segment > head && open != nil {
+			// the chain was written through another proxy if its head moved.
+			stored, err := x.head(ctx, env, chainId, role)
+			if err != nil {
+				return err
+			}
+			if stored != head {
+				t.Lock()
+				if t.open == open {
+					t.head, t.open = stored, nil
+				}
+				t.Unlock()
+				continue
+			}
+		}
+		if head >= 0 && segment > head {
+			return nil
+		}
+		var index *pb.HeaderIndex
+		next := int64(-1)
+		if segment == head && open != nil {
+			index = open
+		} else if segment < head {
+			index, err = x.load(ctx, env, chainId, role, segment)
+			if err == nil && index == nil && open != nil {
+				// the writer seals a missing segment.
+				t.Lock()
+				index, next, err = x.seal(ctx, env, chainId, role, segment)
+				t.Unlock()
+			}
+		}
+		if err == nil && index == nil {
+			index, next, err = x.scan(ctx, env, chainId, role, segment, segment+IndexSegmentSize)
+		}
+		if err != nil {
+			return err
+		}
+		if len(index.Entries) == 0 {
+			if next < 0 {
+				return nil
+			}
+			segment = segmentStart(next)
+			continue
+		}
+		for _, entry := range index.Entries {
+			if entry.BlockNum < start {
+				continue
+			}
+			info := &pb.BlockInfo{
+				Env:       env,
+				ChainId:   chainId,
+				Role:      role,
+				BlockType: pb.BlockInfo_HEADER,
+				BlockNum:  entry.BlockNum,
+				MsgOffset: entry.MsgOffset,
+				BlockHash: entry.BlockHash,
+			}
+			if !fn(info) {
+				return nil
+			}
+		}
+		segment += IndexSegmentSize
+	}
+}
@@ -0,0 +1,52 @@
+package s3
+
+import (
+	"io"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// marshalBlockTo writes the block of info and items to w as proto.Marshal
+// does, the data of the items is written as is instead of being copied.
+func marshalBlockTo(w io.Writer, info *pb.BlockInfo, items []*pb.Data) error {
+	var buf []byte
+	if info != nil {
+		data, err := proto.Marshal(info)
+		if err != nil {
+			return err
+		}
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, data)
+	}
+	for _, item := range items {
+		size := 0
+		if item.Id != 0 {
+			size += protowire.SizeTag(1) + protowire.SizeVarint(uint64(item.Id))
+		}
+		if len(item.Data) > 0 {
+			size += protowire.SizeTag(2) + protowire.SizeBytes(len(item.Data))
+		}
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendVarint(buf, uint64(size))
+		if item.Id != 0 {
+			buf = protowire.AppendTag(buf, 1, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, uint64(item.Id))
+		}
+		if len(item.Data) == 0 {
+			continue
+		}
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendVarint(buf, uint64(len(item.Data)))
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		buf = buf[:0]
+		if _, err := w.Write(item.Data); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
@@ -0,0 +1,105 @@
+package s3
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// ringPoints is the number of points of each proxy on the hash ring.
+	ringPoints = 64
+	// dnsScheme prefixes a proxy address resolved to all the ips of its host.
+	dnsScheme = "dns://"
+)
+
+// hashRing maps keys to proxies by consistent hashing, adding or removing a
+// proxy only moves the keys of its points.
+type hashRing struct {
+	hashes  []uint64
+	proxies []int // the proxy of each point
+	n       int
+}
+
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	// fnv alone clusters similar keys on the ring.
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+func newHashRing(addrs []string) *hashRing {
+	r := &hashRing{n: len(addrs)}
+	type point struct {
+		hash  uint64
+		proxy int
+	}
+	points := make([]point, 0, len(addrs)*ringPoints)
+	for i, addr := range addrs {
+		for j := 0; j < ringPoints; j++ {
+			points = append(points, point{hash: ringHash(fmt.Sprintf("%s#%d", addr, j)), proxy: i})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+	for _, p := range points {
+		r.hashes = append(r.hashes, p.hash)
+		r.proxies = append(r.proxies, p.proxy)
+	}
+	return r
+}
+
+// lookup returns every proxy in the order key fails over to them, the owner
+// of key first.
+func (r *hashRing) lookup(key string) []int {
+	order := make([]int, 0, r.n)
+	seen := make([]bool, r.n)
+	start := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= ringHash(key) })
+	for i := 0; i < len(r.hashes) && len(order) < r.n; i++ {
+		proxy := r.proxies[(start+i)%len(r.hashes)]
+		if !seen[proxy] {
+			seen[proxy] = true
+			order = append(order, proxy)
+		}
+	}
+	return order
+}
+
+// ParseProxyAddrs splits a comma separated list of proxy addresses, an
+// address prefixed with dns:// is resolved to all the ips of its host.
+func ParseProxyAddrs(addr string) ([]string, error) {
+	var addrs []string
+	for _, a := range strings.Split(addr, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if !strings.HasPrefix(a, dnsScheme) {
+			addrs = append(addrs, a)
+			continue
+		}
+		host, port, err := net.SplitHostPort(strings.TrimPrefix(a, dnsScheme))
+		if err != nil {
+			return nil, err
+		}
+		ips, err := net.LookupHost(host)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(ips)
+		for _, ip := range ips {
+			addrs = append(addrs, net.JoinHostPort(ip, port))
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no s3 proxy address in %q", addr)
+	}
+	return addrs, nil
+}
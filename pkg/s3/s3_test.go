@@ -1,20 +1,33 @@
 package s3_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/DeBankDeFi/nodex/pkg/pb"
 	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
 func TestS3(t *testing.T) {
-	go s3.ListenAndServe("0.0.0.0:8765", 32)
+	go s3.ListenAndServe("0.0.0.0:8765", 32, s3.NewMemStore())
 	client, err := s3.NewClient("0.0.0.0:8765")
 	require.NoErrorf(t, err, "NewClient error")
 	header0 := &pb.Block{
@@ -51,3 +64,809 @@ func TestS3(t *testing.T) {
 	require.NoErrorf(t, err, "NewClient error")
 	t.Logf("infos: %v", infos)
 }
+
+func TestListHeaders(t *testing.T) {
+	ctx := context.Background()
+	store := s3.NewMemStore()
+	go s3.ListenAndServe("0.0.0.0:8772", 32, store)
+	client, err := s3.NewClient("0.0.0.0:8772")
+	require.NoErrorf(t, err, "NewClient error")
+	// more headers than a page, two per height from a reorg.
+	for i := int64(0); i < s3.ListPageSize; i++ {
+		for j := int64(0); j < 2; j++ {
+			info := &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: i, MsgOffset: 2*i + j, BlockHash: fmt.Sprint(j)}
+			require.NoError(t, store.Put(ctx, utils.HeaderPrefix(info), nil))
+		}
+	}
+
+	var infos []*pb.BlockInfo
+	require.Eventually(t, func() bool {
+		infos = nil
+		err = client.ListHeaders(ctx, "256", "test", "master", 10, 900, 30, func(info *pb.BlockInfo) bool {
+			infos = append(infos, info)
+			return true
+		})
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	require.Len(t, infos, 2*(900-15)-1)
+	require.Equal(t, int64(31), infos[0].MsgOffset)
+	require.Equal(t, int64(899), infos[len(infos)-1].BlockNum)
+
+	count := 0
+	err = client.ListHeaders(ctx, "256", "test", "master", 0, 0, -1, func(info *pb.BlockInfo) bool {
+		count++
+		return count < 1500
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1500, count)
+}
+
+// listCounter counts the LIST calls to a MemStore.
+type listCounter struct {
+	*s3.MemStore
+	lists int32
+}
+
+func (l *listCounter) List(ctx context.Context, prefix string, startAfter string, maxKeys int32) ([]string, error) {
+	atomic.AddInt32(&l.lists, 1)
+	return l.MemStore.List(ctx, prefix, startAfter, maxKeys)
+}
+
+func TestHeaderIndex(t *testing.T) {
+	ctx := context.Background()
+	store := &listCounter{MemStore: s3.NewMemStore()}
+	go s3.ListenAndServe("0.0.0.0:8773", 32, store)
+	client, err := s3.NewClient("0.0.0.0:8773")
+	require.NoErrorf(t, err, "NewClient error")
+	var infos []*pb.BlockInfo
+	for i := int64(0); i < 5; i++ {
+		infos = append(infos, &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: i, MsgOffset: i, BlockHash: fmt.Sprint(i), BlockType: pb.BlockInfo_HEADER})
+	}
+	// a reorg replaces block 2.
+	infos = append(infos, &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 2, MsgOffset: 5, BlockHash: "2b", BlockType: pb.BlockInfo_HEADER})
+	require.Eventually(t, func() bool {
+		return client.PutBlock(ctx, &pb.Block{Info: proto.Clone(infos[0]).(*pb.BlockInfo)}) == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	for _, info := range infos[1:] {
+		require.NoError(t, client.PutBlock(ctx, &pb.Block{Info: proto.Clone(info).(*pb.BlockInfo)}))
+	}
+	// only the first header listed the open segment.
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.lists))
+
+	listed, err := client.ListHeaderStartAt(ctx, "256", "test", "master", 2, 3, 1)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.lists))
+	require.Len(t, listed, 3)
+	for i, expected := range []*pb.BlockInfo{infos[2], infos[5], infos[3]} {
+		require.Equal(t, expected.BlockNum, listed[i].BlockNum)
+		require.Equal(t, expected.MsgOffset, listed[i].MsgOffset)
+		require.Equal(t, expected.BlockHash, listed[i].BlockHash)
+	}
+
+	// listings from the tip do not LIST the store.
+	tip := func(start int64) (listed []int64) {
+		err := client.ListHeaders(ctx, "256", "test", "master", start, start+3, -1, func(info *pb.BlockInfo) bool {
+			listed = append(listed, info.BlockNum)
+			return true
+		})
+		require.NoError(t, err)
+		return listed
+	}
+	require.Equal(t, []int64{3, 4}, tip(3))
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.lists))
+
+	// a header put through another proxy is not lost, the segment is sealed
+	// from a LIST of its headers once a header above it is put.
+	serve(t, "127.0.0.1:8788", store)
+	other, err := s3.NewClient("127.0.0.1:8788")
+	require.NoErrorf(t, err, "NewClient error")
+	late := &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 5, MsgOffset: 7, BlockHash: "5", BlockType: pb.BlockInfo_HEADER}
+	require.NoError(t, other.PutBlock(ctx, &pb.Block{Info: late}))
+	next := &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: s3.IndexSegmentSize, MsgOffset: 6, BlockHash: "next", BlockType: pb.BlockInfo_HEADER}
+	require.NoError(t, client.PutBlock(ctx, &pb.Block{Info: next}))
+	lists := atomic.LoadInt32(&store.lists)
+	require.Equal(t, []int64{3, 4, 5}, tip(3))
+	require.Equal(t, []int64{s3.IndexSegmentSize}, tip(s3.IndexSegmentSize-1))
+	require.Equal(t, []int64{s3.IndexSegmentSize}, tip(s3.IndexSegmentSize))
+	require.Equal(t, lists, atomic.LoadInt32(&store.lists))
+	// the other proxy drops its open segment once the head moved.
+	listed, err = other.ListHeaderStartAt(ctx, "256", "test", "master", s3.IndexSegmentSize, 1, -1)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, next.BlockHash, listed[0].BlockHash)
+
+	_, err = client.RemoveFilesBefore(ctx, infos[:2], time.Time{}, false)
+	require.NoError(t, err)
+	listed, err = client.ListHeaderStartAt(ctx, "256", "test", "master", 0, 1, -1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), listed[0].BlockNum)
+}
+
+func TestBlobStore(t *testing.T) {
+	fsStore, err := s3.NewFsStore(t.TempDir())
+	require.NoErrorf(t, err, "NewFsStore error")
+	for _, store := range []s3.BlobStore{s3.NewMemStore(), fsStore} {
+		ctx := context.Background()
+		val, err := store.Get(ctx, "test/256/master/header/000000000001/000000000000/1")
+		require.NoErrorf(t, err, "Get error")
+		require.Nil(t, val)
+
+		for _, key := range []string{
+			"test/256/master/header/000000000003/000000000002/3",
+			"test/256/master/header/000000000001/000000000000/1",
+			"test/256/master/header/000000000002/000000000001/2",
+			"test/256/master/block/1",
+		} {
+			require.NoErrorf(t, store.Put(ctx, key, []byte(key)), "Put error")
+		}
+		val, err = store.Get(ctx, "test/256/master/block/1")
+		require.NoErrorf(t, err, "Get error")
+		require.Equal(t, []byte("test/256/master/block/1"), val)
+
+		keys, err := store.List(ctx, "test/256/master/header", "test/256/master/header/000000000001", 2)
+		require.NoErrorf(t, err, "List error")
+		require.Equal(t, []string{
+			"test/256/master/header/000000000001/000000000000/1",
+			"test/256/master/header/000000000002/000000000001/2",
+		}, keys)
+
+		stat, err := store.Stat(ctx, "test/256/master/block/1")
+		require.NoErrorf(t, err, "Stat error")
+		require.Equal(t, int64(len("test/256/master/block/1")), stat.Size)
+		require.False(t, stat.ModTime.IsZero())
+
+		require.NoErrorf(t, store.Delete(ctx, "test/256/master/block/1"), "Delete error")
+		require.NoErrorf(t, store.Delete(ctx, "test/256/master/block/1"), "Delete error")
+		val, err = store.Get(ctx, "test/256/master/block/1")
+		require.NoErrorf(t, err, "Get error")
+		require.Nil(t, val)
+		stat, err = store.Stat(ctx, "test/256/master/block/1")
+		require.NoErrorf(t, err, "Stat error")
+		require.Nil(t, stat)
+
+		val, err = store.GetRange(ctx, "test/256/master/header/000000000001/000000000000/1", 5, 3)
+		require.NoErrorf(t, err, "GetRange error")
+		require.Equal(t, []byte("256"), val)
+		val, err = store.GetRange(ctx, "test/256/master/header/000000000001/000000000000/1", 46, 0)
+		require.NoErrorf(t, err, "GetRange error")
+		require.Equal(t, []byte("00/1"), val)
+		val, err = store.GetRange(ctx, "test/256/master/header/000000000001/000000000000/1", 50, 0)
+		require.NoErrorf(t, err, "GetRange error")
+		require.Empty(t, val)
+		val, err = store.GetRange(ctx, "test/256/master/block/1", 1, 0)
+		require.NoErrorf(t, err, "GetRange error")
+		require.Nil(t, val)
+
+		// a written object only shows once closed, an aborted one never.
+		w, err := store.NewWriter(ctx, "test/256/master/block/2")
+		require.NoErrorf(t, err, "NewWriter error")
+		_, err = w.Write([]byte("test/256/master/block/2"))
+		require.NoError(t, err)
+		val, err = store.Get(ctx, "test/256/master/block/2")
+		require.NoErrorf(t, err, "Get error")
+		require.Nil(t, val)
+		require.NoError(t, w.Close())
+		val, err = store.Get(ctx, "test/256/master/block/2")
+		require.NoErrorf(t, err, "Get error")
+		require.Equal(t, []byte("test/256/master/block/2"), val)
+		w, err = store.NewWriter(ctx, "test/256/master/block/3")
+		require.NoErrorf(t, err, "NewWriter error")
+		_, err = w.Write([]byte("test/256/master/block/3"))
+		require.NoError(t, err)
+		require.NoError(t, w.Abort())
+		val, err = store.Get(ctx, "test/256/master/block/3")
+		require.NoErrorf(t, err, "Get error")
+		require.Nil(t, val)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	block := &pb.Block{
+		Info: &pb.BlockInfo{
+			ChainId:   "256",
+			Env:       "test",
+			BlockNum:  1,
+			BlockHash: "1",
+			BlockType: pb.BlockInfo_DATA,
+		}}
+	block.BatchItems = append(block.BatchItems, &pb.Data{
+		Id: 0, Data: bytes.Repeat([]byte("leveldb batch"), 1<<16)})
+	data, err := proto.Marshal(block)
+	require.NoErrorf(t, err, "Marshal error")
+
+	for _, codec := range []pb.BlockInfo_Compression{pb.BlockInfo_NONE, pb.BlockInfo_ZSTD, pb.BlockInfo_SNAPPY} {
+		compressed, err := s3.Compress(codec, data)
+		require.NoErrorf(t, err, "Compress error")
+		require.Equal(t, codec, s3.DetectCompression(compressed))
+		if codec != pb.BlockInfo_NONE {
+			require.Less(t, len(compressed), len(data))
+		}
+		decompressed, err := s3.Decompress(codec, compressed)
+		require.NoErrorf(t, err, "Decompress error")
+		require.Equal(t, data, decompressed)
+		// the codec of objects without a recorded one is detected.
+		decompressed, err = s3.Decompress(pb.BlockInfo_NONE, compressed)
+		require.NoErrorf(t, err, "Decompress error")
+		require.Equal(t, data, decompressed)
+	}
+	// uncompressed data is not mistaken for a frame it starts like.
+	framed, err := s3.Compress(pb.BlockInfo_ZSTD, data)
+	require.NoErrorf(t, err, "Compress error")
+	decompressed, err := s3.Decompress(pb.BlockInfo_UNCOMPRESSED, framed)
+	require.NoErrorf(t, err, "Decompress error")
+	require.Equal(t, framed, decompressed)
+
+	codec, err := s3.ParseCompression("zstd")
+	require.NoErrorf(t, err, "ParseCompression error")
+	require.Equal(t, pb.BlockInfo_ZSTD, codec)
+	codec, err = s3.ParseCompression("")
+	require.NoErrorf(t, err, "ParseCompression error")
+	require.Equal(t, pb.BlockInfo_UNCOMPRESSED, codec)
+	_, err = s3.ParseCompression("lz4")
+	require.Error(t, err)
+}
+
+func TestChecksum(t *testing.T) {
+	store := s3.NewMemStore()
+	go s3.ListenAndServe("0.0.0.0:8766", 32, store)
+	client, err := s3.NewClient("0.0.0.0:8766")
+	require.NoErrorf(t, err, "NewClient error")
+	block := &pb.Block{
+		Info: &pb.BlockInfo{
+			ChainId:   "256",
+			Env:       "test",
+			Role:      "master",
+			BlockNum:  1,
+			BlockHash: "1",
+			BlockType: pb.BlockInfo_DATA,
+		}}
+	block.BatchItems = append(block.BatchItems, &pb.Data{Id: 0, Data: []byte("data")})
+	require.Eventually(t, func() bool {
+		info, err := client.PutBlockInfo(context.Background(), block)
+		if err != nil {
+			return false
+		}
+		block.Info = info
+		return true
+	}, 5*time.Second, 100*time.Millisecond)
+	require.NotEmpty(t, block.Info.DataChecksum)
+	require.Empty(t, block.Info.HeaderChecksum)
+
+	val, err := client.GetBlock(context.Background(), block.Info, true)
+	require.NoErrorf(t, err, "GetBlock error")
+	require.Equal(t, []byte("data"), val.BatchItems[0].Data)
+
+	key := utils.InfoToPrefix(block.Info)
+	data, err := store.Get(context.Background(), key)
+	require.NoErrorf(t, err, "Get error")
+	require.NoErrorf(t, store.Put(context.Background(), key, data[:len(data)-1]), "Put error")
+	_, err = client.GetBlock(context.Background(), block.Info, true)
+	require.ErrorIs(t, err, utils.ErrChecksumMismatch)
+}
+
+type getCounter struct {
+	*s3.MemStore
+	gets int32
+}
+
+func (g *getCounter) Get(ctx context.Context, key string) ([]byte, error) {
+	atomic.AddInt32(&g.gets, 1)
+	return g.MemStore.Get(ctx, key)
+}
+
+func serve(t *testing.T, addr string, store s3.BlobStore) *grpc.Server {
+	srv, err := s3.NewServer(store)
+	require.NoErrorf(t, err, "NewServer error")
+	ln, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func serveDiskCache(t *testing.T, addr string, store s3.BlobStore, dir string) *s3.Client {
+	s3.DiskCacheDir = dir
+	s3.DiskCacheBytes = 1500
+	serve(t, addr, store)
+	s3.DiskCacheDir = ""
+	client, err := s3.NewClient(addr)
+	require.NoErrorf(t, err, "NewClient error")
+	return client
+}
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := &getCounter{MemStore: s3.NewMemStore()}
+	client := serveDiskCache(t, "0.0.0.0:8774", store, dir)
+	blocks := make([]*pb.Block, 2)
+	for i := range blocks {
+		data := make([]byte, 1000)
+		rand.Read(data)
+		blocks[i] = &pb.Block{
+			Info:       &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: int64(i), BlockHash: fmt.Sprint(i), BlockType: pb.BlockInfo_DATA},
+			BatchItems: []*pb.Data{{Id: 0, Data: data}},
+		}
+		require.NoError(t, client.PutBlock(ctx, blocks[i]))
+	}
+	// only the last put block fits the disk budget.
+	block, err := client.GetBlock(ctx, blocks[1].Info, true)
+	require.NoError(t, err)
+	require.Equal(t, blocks[1].BatchItems[0].Data, block.BatchItems[0].Data)
+	require.Equal(t, int32(0), atomic.LoadInt32(&store.gets))
+	block, err = client.GetBlock(ctx, blocks[0].Info, true)
+	require.NoError(t, err)
+	require.Equal(t, blocks[0].BatchItems[0].Data, block.BatchItems[0].Data)
+	t.Log(atomic.LoadInt32(&store.gets))
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.gets))
+
+	// the cache survives a restart.
+	client = serveDiskCache(t, "0.0.0.0:8775", store, dir)
+	block, err = client.GetBlock(ctx, blocks[0].Info, true)
+	require.NoError(t, err)
+	require.Equal(t, blocks[0].BatchItems[0].Data, block.BatchItems[0].Data)
+	t.Log(atomic.LoadInt32(&store.gets))
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.gets))
+}
+
+func TestEncryptedDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	key := make([]byte, 32)
+	rand.Read(key)
+	keyring, err := s3.NewKeyring("k1", map[string][]byte{"k1": key})
+	require.NoError(t, err)
+	store := &getCounter{MemStore: s3.NewMemStore()}
+	client := serveDiskCache(t, "0.0.0.0:8787", s3.NewEncryptedStore(store, keyring), dir)
+	data := make([]byte, 500)
+	rand.Read(data)
+	block := &pb.Block{
+		Info:       &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 1, BlockHash: "1", BlockType: pb.BlockInfo_DATA},
+		BatchItems: []*pb.Data{{Id: 0, Data: data}},
+	}
+	require.NoError(t, client.PutBlock(ctx, block))
+
+	// blocks of an encrypted store are not kept in plaintext on disk.
+	var files []string
+	require.NoError(t, filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		buf, err := os.ReadFile(p)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(buf, data))
+		files = append(files, p)
+		return nil
+	}))
+	require.Len(t, files, 1)
+	got, err := client.GetBlock(ctx, block.Info, true)
+	require.NoError(t, err)
+	require.Equal(t, data, got.BatchItems[0].Data)
+	require.Equal(t, int32(0), atomic.LoadInt32(&store.gets))
+
+	// a cached block failing to open is read from the store again.
+	buf, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	buf[len(buf)-1] ^= 1
+	require.NoError(t, os.WriteFile(files[0], buf, 0644))
+	got, err = client.GetBlock(ctx, block.Info, true)
+	require.NoError(t, err)
+	require.Equal(t, data, got.BatchItems[0].Data)
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.gets))
+	got, err = client.GetBlock(ctx, block.Info, true)
+	require.NoError(t, err)
+	require.Equal(t, data, got.BatchItems[0].Data)
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.gets))
+}
+
+func TestProxyRing(t *testing.T) {
+	ctx := context.Background()
+	store := &getCounter{MemStore: s3.NewMemStore()}
+	addrs := []string{"127.0.0.1:8776", "127.0.0.1:8777", "127.0.0.1:8778"}
+	servers := make([]*grpc.Server, len(addrs))
+	for i, addr := range addrs {
+		servers[i] = serve(t, addr, store)
+	}
+	client, err := s3.NewClient(strings.Join(addrs, ","))
+	require.NoErrorf(t, err, "NewClient error")
+	client.SetReplicas(2)
+	blocks := make([]*pb.Block, 8)
+	for i := range blocks {
+		blocks[i] = &pb.Block{
+			Info:       &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: int64(i), BlockHash: fmt.Sprint(i), BlockType: pb.BlockInfo_DATA},
+			BatchItems: []*pb.Data{{Id: 0, Data: []byte(fmt.Sprint(i))}},
+		}
+		require.NoError(t, client.PutBlock(ctx, blocks[i]))
+	}
+	// a block read without the proxy caches is cached apart from the one
+	// returned.
+	got, err := client.GetBlock(ctx, blocks[0].Info, true)
+	require.NoError(t, err)
+	got.BatchItems = nil
+	got, err = client.GetBlock(ctx, blocks[0].Info, false)
+	require.NoError(t, err)
+	require.Equal(t, blocks[0].BatchItems[0].Data, got.BatchItems[0].Data)
+	atomic.StoreInt32(&store.gets, 0)
+	// every proxy was warmed, the blocks are served from their caches while
+	// the proxies go down one by one. The headers of a chain are put through
+	// its owner only and fail once it is down.
+	header := &pb.Block{Info: &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockType: pb.BlockInfo_HEADER}}
+	var ownerDown bool
+	for i, srv := range servers {
+		client, err := s3.NewClient(strings.Join(addrs, ","))
+		require.NoErrorf(t, err, "NewClient error")
+		for _, block := range blocks {
+			got, err := client.GetBlock(ctx, block.Info, false)
+			require.NoError(t, err)
+			require.Equal(t, block.BatchItems[0].Data, got.BatchItems[0].Data)
+		}
+		require.Equal(t, int32(0), atomic.LoadInt32(&store.gets))
+		header.Info.BlockNum = int64(i)
+		header.Info.BlockHash = fmt.Sprint(i)
+		err = client.PutBlock(ctx, header)
+		if ownerDown {
+			require.Error(t, err)
+		} else {
+			ownerDown = err != nil
+		}
+		// the owner reads the head of its header index.
+		atomic.StoreInt32(&store.gets, 0)
+		srv.Stop()
+	}
+	require.True(t, ownerDown)
+}
+
+func TestWatchBlocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &getCounter{MemStore: s3.NewMemStore()}
+	serve(t, "127.0.0.1:8779", store)
+	writer, err := s3.NewClient("127.0.0.1:8779")
+	require.NoErrorf(t, err, "NewClient error")
+	reader, err := s3.NewClient("127.0.0.1:8779")
+	require.NoErrorf(t, err, "NewClient error")
+	go reader.WatchBlocks(ctx, "test", "256", "master")
+
+	// blocks put once the watch is subscribed are pushed to the reader.
+	i := 0
+	require.Eventually(t, func() bool {
+		block := &pb.Block{
+			Info:       &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: int64(i), BlockHash: fmt.Sprint(i), BlockType: pb.BlockInfo_DATA},
+			BatchItems: []*pb.Data{{Id: 0, Data: []byte(fmt.Sprint(i))}},
+		}
+		i++
+		require.NoError(t, writer.PutBlock(ctx, block))
+		time.Sleep(10 * time.Millisecond)
+		gets := atomic.LoadInt32(&store.gets)
+		got, err := reader.GetBlock(ctx, block.Info, true)
+		require.NoError(t, err)
+		require.Equal(t, block.BatchItems[0].Data, got.BatchItems[0].Data)
+		return atomic.LoadInt32(&store.gets) == gets
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestMultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	partSize := s3.PartSize
+	s3.PartSize = 1 << 20
+	defer func() { s3.PartSize = partSize }()
+	store := &getCounter{MemStore: s3.NewMemStore()}
+	serve(t, "127.0.0.1:8780", store)
+	for i, codec := range []pb.BlockInfo_Compression{pb.BlockInfo_NONE, pb.BlockInfo_ZSTD} {
+		client, err := s3.NewClient("127.0.0.1:8780")
+		require.NoErrorf(t, err, "NewClient error")
+		client.SetCompression(codec)
+		data := make([]byte, 3<<20)
+		rand.Read(data)
+		block := &pb.Block{
+			Info: &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: int64(i), BlockHash: fmt.Sprint(i), BlockType: pb.BlockInfo_DATA},
+			BatchItems: []*pb.Data{
+				{Id: 0, Data: data},
+				{Id: -1},
+				{Id: 5, Data: []byte("5")},
+			},
+		}
+		expected := proto.Clone(block).(*pb.Block)
+		info, err := client.PutBlockInfo(ctx, block)
+		require.NoError(t, err)
+		require.Greater(t, info.BlockSize, int64(s3.PartSize))
+		block.Info = info
+
+		// a block above PartSize is not cached by the proxy.
+		for j := 0; j < 2; j++ {
+			gets := atomic.LoadInt32(&store.gets)
+			got, err := client.GetBlock(ctx, block.Info, true)
+			require.NoError(t, err)
+			require.Equal(t, len(expected.BatchItems), len(got.BatchItems))
+			for k, item := range expected.BatchItems {
+				require.True(t, proto.Equal(item, got.BatchItems[k]))
+			}
+			require.Equal(t, gets+1, atomic.LoadInt32(&store.gets))
+		}
+	}
+}
+
+// flakyProxy cuts every download after its first chunk.
+type flakyProxy struct {
+	pb.UnimplementedS3ProxyServer
+	sync.Mutex
+	data    []byte
+	offsets []int64
+}
+
+func (f *flakyProxy) GetBlock(req *pb.GetBlockRequest, stream pb.S3Proxy_GetBlockServer) error {
+	f.Lock()
+	f.offsets = append(f.offsets, req.Offset)
+	f.Unlock()
+	data := f.data[req.Offset:]
+	if len(data) <= 1<<10 {
+		return stream.Send(&pb.BlockChunk{Chunk: data})
+	}
+	if err := stream.Send(&pb.BlockChunk{Chunk: data[:1<<10]}); err != nil {
+		return err
+	}
+	return status.Error(codes.Unavailable, "cut")
+}
+
+func TestRangedGetBlock(t *testing.T) {
+	ctx := context.Background()
+	store := s3.NewMemStore()
+	serve(t, "127.0.0.1:8781", store)
+	data := make([]byte, 3000)
+	rand.Read(data)
+	info := &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 1, BlockType: pb.BlockInfo_DATA}
+	require.NoError(t, store.Put(ctx, utils.InfoToPrefix(info), data))
+	conn, err := grpc.Dial("127.0.0.1:8781", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	stream, err := pb.NewS3ProxyClient(conn).GetBlock(ctx, &pb.GetBlockRequest{
+		Info:   info,
+		Offset: 1000,
+		Length: 1500,
+	})
+	require.NoError(t, err)
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, data[1000:2500], chunk.Chunk)
+
+	// a cut download resumes from the last received byte.
+	block := &pb.Block{
+		Info:       &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 2, BlockType: pb.BlockInfo_DATA},
+		BatchItems: []*pb.Data{{Id: 0, Data: data}},
+	}
+	buf, err := proto.Marshal(block)
+	require.NoError(t, err)
+	s3.SetChecksum(block.Info, s3.Checksum(buf))
+	flaky := &flakyProxy{data: buf}
+	srv := grpc.NewServer()
+	pb.RegisterS3ProxyServer(srv, flaky)
+	ln, err := net.Listen("tcp", "127.0.0.1:8782")
+	require.NoError(t, err)
+	go srv.Serve(ln)
+	defer srv.Stop()
+	client, err := s3.NewClient("127.0.0.1:8782")
+	require.NoErrorf(t, err, "NewClient error")
+	got, err := client.GetBlock(ctx, block.Info, true)
+	require.NoError(t, err)
+	require.Equal(t, data, got.BatchItems[0].Data)
+	require.Equal(t, []int64{0, 1 << 10, 2 << 10}, flaky.offsets)
+}
+
+// slowStore holds the next Get until its context is done once slow is set.
+type slowStore struct {
+	*s3.MemStore
+	slow     int32
+	gets     int32
+	canceled int32
+}
+
+func (s *slowStore) Get(ctx context.Context, key string) ([]byte, error) {
+	atomic.AddInt32(&s.gets, 1)
+	if atomic.CompareAndSwapInt32(&s.slow, 1, 0) {
+		<-ctx.Done()
+		atomic.AddInt32(&s.canceled, 1)
+		return nil, ctx.Err()
+	}
+	return s.MemStore.Get(ctx, key)
+}
+
+func TestHedgedReads(t *testing.T) {
+	ctx := context.Background()
+	store := &slowStore{MemStore: s3.NewMemStore()}
+	serve(t, "127.0.0.1:8783", store)
+	client, err := s3.NewClient("127.0.0.1:8783")
+	require.NoErrorf(t, err, "NewClient error")
+	require.NoError(t, store.Put(ctx, "file", []byte("file")))
+	for i := 0; i < 100; i++ {
+		_, err := client.GetFile(ctx, "file")
+		require.NoError(t, err)
+	}
+
+	// a read stuck past the latency percentile is hedged by a second one.
+	atomic.StoreInt32(&store.slow, 1)
+	gets := atomic.LoadInt32(&store.gets)
+	start := time.Now()
+	buf, err := client.GetFile(ctx, "file")
+	require.NoError(t, err)
+	require.Equal(t, []byte("file"), buf)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, gets+2, atomic.LoadInt32(&store.gets))
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&store.canceled) == 1 }, time.Second, time.Millisecond)
+
+	// a block read is not hedged against the latencies of file reads.
+	block := &pb.Block{
+		Info:       &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 1, BlockHash: "1", BlockType: pb.BlockInfo_DATA},
+		BatchItems: []*pb.Data{{Id: 0, Data: []byte("data")}},
+	}
+	info, err := client.PutBlockInfo(ctx, block)
+	require.NoError(t, err)
+	atomic.StoreInt32(&store.slow, 1)
+	gets = atomic.LoadInt32(&store.gets)
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = client.GetBlock(timeout, info, true)
+	require.Error(t, err)
+	require.Equal(t, gets+1, atomic.LoadInt32(&store.gets))
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&store.canceled) == 2 }, time.Second, time.Millisecond)
+
+	// the deadline of the request ends its store read.
+	s3.HedgePercentile = 0
+	defer func() { s3.HedgePercentile = 0.95 }()
+	atomic.StoreInt32(&store.slow, 1)
+	timeout, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = client.GetFile(timeout, "file")
+	require.Error(t, err)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&store.canceled) == 3 }, time.Second, time.Millisecond)
+}
+
+// gatedStore holds its reads until release is closed.
+type gatedStore struct {
+	*s3.MemStore
+	release chan struct{}
+	gets    int32
+}
+
+func (s *gatedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	atomic.AddInt32(&s.gets, 1)
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.MemStore.Get(ctx, key)
+}
+
+func TestSharedRead(t *testing.T) {
+	ctx := context.Background()
+	mem := s3.NewMemStore()
+	serve(t, "127.0.0.1:8785", mem)
+	writer, err := s3.NewClient("127.0.0.1:8785")
+	require.NoErrorf(t, err, "NewClient error")
+	block := &pb.Block{
+		Info:       &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 1, BlockHash: "1", BlockType: pb.BlockInfo_DATA},
+		BatchItems: []*pb.Data{{Id: 1, Data: []byte("1")}},
+	}
+	info, err := writer.PutBlockInfo(ctx, block)
+	require.NoError(t, err)
+
+	store := &gatedStore{MemStore: mem, release: make(chan struct{})}
+	serve(t, "127.0.0.1:8786", store)
+	first, err := s3.NewClient("127.0.0.1:8786")
+	require.NoErrorf(t, err, "NewClient error")
+	second, err := s3.NewClient("127.0.0.1:8786")
+	require.NoErrorf(t, err, "NewClient error")
+
+	// the request starting a shared read is canceled while another waits on it.
+	canceled, cancel := context.WithCancel(ctx)
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := first.GetBlock(canceled, info, false)
+		firstErr <- err
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&store.gets) == 1 }, time.Second, time.Millisecond)
+	type result struct {
+		block *pb.Block
+		err   error
+	}
+	secondRes := make(chan result, 1)
+	go func() {
+		got, err := second.GetBlock(ctx, info, false)
+		secondRes <- result{block: got, err: err}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	require.Error(t, <-firstErr)
+	// the proxy sees the cancel after the client.
+	time.Sleep(100 * time.Millisecond)
+	close(store.release)
+	res := <-secondRes
+	require.NoError(t, res.err)
+	require.True(t, proto.Equal(block.BatchItems[0], res.block.BatchItems[0]))
+	t.Log(atomic.LoadInt32(&store.gets))
+	require.Equal(t, int32(1), atomic.LoadInt32(&store.gets))
+}
+
+func TestEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	key1, key2 := make([]byte, 32), make([]byte, 32)
+	rand.Read(key1)
+	rand.Read(key2)
+	old, err := s3.NewKeyring("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+	raw := s3.NewMemStore()
+	store := s3.NewEncryptedStore(raw, old)
+	require.NoError(t, raw.Put(ctx, "plain", []byte("plain")))
+
+	data := make([]byte, 200<<10)
+	rand.Read(data)
+	require.NoError(t, store.Put(ctx, "put", data))
+	w, err := store.NewWriter(ctx, "written")
+	require.NoError(t, err)
+	for i := 0; i < len(data); i += 1000 {
+		end := i + 1000
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err = w.Write(data[i:end])
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	for _, key := range []string{"put", "written"} {
+		sealed, err := raw.Get(ctx, key)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(sealed, data[:64]))
+		buf, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, data, buf)
+		for _, r := range [][2]int64{{0, 10}, {65530, 20}, {65536, 65536}, {100000, 0}, {int64(len(data)) - 1, 0}, {int64(len(data)), 0}} {
+			buf, err := store.GetRange(ctx, key, r[0], r[1])
+			require.NoError(t, err)
+			end := int64(len(data))
+			if r[1] > 0 {
+				end = r[0] + r[1]
+			}
+			require.Equal(t, data[r[0]:end], buf)
+		}
+	}
+	buf, err := store.Get(ctx, "plain")
+	require.NoError(t, err)
+	require.Equal(t, []byte("plain"), buf)
+
+	// a truncated object does not open.
+	sealed, err := raw.Get(ctx, "put")
+	require.NoError(t, err)
+	require.NoError(t, raw.Put(ctx, "truncated", sealed[:len(sealed)-len(data)%(64<<10)-16]))
+	_, err = store.Get(ctx, "truncated")
+	require.ErrorIs(t, err, utils.ErrDecryptFailed)
+
+	// after a rotation, the rekeyed objects open without the old key.
+	rotated, err := s3.NewKeyring("k2", map[string][]byte{"k1": key1, "k2": key2})
+	require.NoError(t, err)
+	require.NoError(t, raw.Delete(ctx, "truncated"))
+	indexKey := utils.HeaderIndexKey("test", "256", "master", 0)
+	require.NoError(t, store.Put(ctx, indexKey, data[:100]))
+	n, err := s3.NewEncryptedStore(raw, rotated).Rekey(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	// the header index is removed for the proxies to seal it again.
+	stat, err := raw.Stat(ctx, indexKey)
+	require.NoError(t, err)
+	require.Nil(t, stat)
+	retired, err := s3.NewKeyring("k2", map[string][]byte{"k2": key2})
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "put")
+	require.ErrorIs(t, err, utils.ErrUnknownKey)
+	store = s3.NewEncryptedStore(raw, retired)
+	for _, key := range []string{"put", "written"} {
+		buf, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, data, buf)
+	}
+	buf, err = store.Get(ctx, "plain")
+	require.NoError(t, err)
+	require.Equal(t, []byte("plain"), buf)
+	sealed, err = raw.Get(ctx, "plain")
+	require.NoError(t, err)
+	require.NotEqual(t, []byte("plain"), sealed)
+}
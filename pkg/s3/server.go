@@ -3,67 +3,99 @@ package s3
 import (
 	"bytes"
 	"context"
-	"errors"
-	"fmt"
 	"io"
 	"math"
 	"net"
 	"sync"
 	"time"
 
+	"github.com/DeBankDeFi/nodex/pkg/lib/broker"
 	"github.com/DeBankDeFi/nodex/pkg/metrics"
 	"github.com/DeBankDeFi/nodex/pkg/pb"
 	"github.com/DeBankDeFi/nodex/pkg/utils"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/status"
 )
 
 const (
-	BucketName = "prod-blockchain-replicator"
-	ChunkSize  = 1 << 22
-	Region     = "ap-northeast-1"
+	ChunkSize = 1 << 22
+	// MinPartSize is the min size of a part of an S3 multipart upload.
+	MinPartSize = 5 << 20
+	// ListPageSize is the number of keys listed at once by ListHeaders.
+	ListPageSize = 1000
+	// SharedReadTimeout bounds a store read shared by concurrent GetBlock
+	// requests, it does not end with any of them.
+	SharedReadTimeout = time.Minute
 )
 
 var MaxCacheSize uint = 256
 
+// PartSize bounds the memory of an upload through the proxy, larger blocks
+// are streamed to the store in parts of PartSize bytes and not cached.
+var PartSize = 16 << 20
+
+// MaxCacheBytes is the memory budget of the block caches of the proxy and
+// clients, 0 disables it.
+var MaxCacheBytes int64 = 1 << 30
+
+// DiskCacheDir is the directory of the on-disk block cache of the proxy,
+// empty disables it.
+var DiskCacheDir string
+
+// DiskCacheBytes is the disk budget of the on-disk block cache, 0 disables it.
+var DiskCacheBytes int64 = 10 << 30
+
 type server struct {
 	pb.UnimplementedS3ProxyServer
-	s3    *s3.Client
+	store BlobStore
+	reads *storeReader
+	index *headerIndex
 	cache *utils.Cache
+	disk  *diskCache
+	// watchers are the brokers of the blocks put through the proxy by chain.
+	watchers map[string]*broker.Broker[pb.WatchBlocksReply]
 	sync.RWMutex
 	getPool  sync.Pool
 	s3Metric *metrics.S3Metrics
 }
 
-func ListenAndServe(addr string, cacheSize uint) error {
+func ListenAndServe(addr string, cacheSize uint, store BlobStore) error {
 	MaxCacheSize = cacheSize
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return err
 	}
-	srv, err := NewServer()
+	srv, err := NewServer(store)
 	if err != nil {
 		return err
 	}
 	return srv.Serve(ln)
 }
 
-func NewServer() (*grpc.Server, error) {
-	sdkConfig, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(Region))
-	if err != nil {
-		return nil, err
+func NewServer(store BlobStore) (*grpc.Server, error) {
+	var disk *diskCache
+	if DiskCacheDir != "" {
+		// blocks of an encrypted store are encrypted on disk too.
+		var keyring *Keyring
+		if encrypted, ok := store.(*EncryptedStore); ok {
+			keyring = encrypted.keyring
+		}
+		var err error
+		disk, err = newDiskCache(DiskCacheDir, DiskCacheBytes, keyring)
+		if err != nil {
+			return nil, err
+		}
 	}
-	s3 := s3.NewFromConfig(sdkConfig)
 	s := grpc.NewServer(grpc.MaxRecvMsgSize(math.MaxInt32),
 		grpc.MaxSendMsgSize(math.MaxInt32))
 	pb.RegisterS3ProxyServer(s, &server{
-		cache: utils.NewCache(MaxCacheSize),
-		s3:    s3,
+		cache:    utils.NewCache("s3-proxy", MaxCacheSize, MaxCacheBytes),
+		disk:     disk,
+		watchers: make(map[string]*broker.Broker[pb.WatchBlocksReply]),
+		store:    store,
+		reads:    newStoreReader(store),
+		index:    newHeaderIndex(store),
 		getPool: sync.Pool{
 			New: func() interface{} {
 				buf := make([]byte, ChunkSize)
@@ -75,48 +107,138 @@ func NewServer() (*grpc.Server, error) {
 	return s, nil
 }
 
-func (s *server) s3GetFile(key string) (buf []byte, err error) {
+// s3GetFile reads the object at key from the store until ctx is done, the
+// read is hedged against the recent reads of kind.
+func (s *server) s3GetFile(ctx context.Context, kind, key string) (buf []byte, err error) {
 	timeStart := time.Now()
-	result, err := s.s3.GetObject(context.Background(), &s3.GetObjectInput{
-		Bucket: aws.String(BucketName),
-		Key:    aws.String(key),
-	})
-	if result != nil {
-		defer result.Body.Close()
+	buf, err = s.reads.read(ctx, kind, func(ctx context.Context) ([]byte, error) { return s.store.Get(ctx, key) })
+	if err != nil {
+		return nil, err
+	}
+	s.s3Metric.ObserveReadLatency(s.store.Name(), float64(time.Since(timeStart).Milliseconds()))
+	s.s3Metric.IncreaseReadSize(s.store.Name(), int64(len(buf)))
+	return buf, nil
+}
+
+// getBlockFile reads a block from the disk cache, then from the store caching
+// it on disk.
+func (s *server) getBlockFile(ctx context.Context, info *pb.BlockInfo, key string) ([]byte, error) {
+	if s.disk == nil {
+		return s.s3GetFile(ctx, info.BlockType.String(), key)
 	}
+	buf, err := s.disk.get(context.Background(), key)
 	if err != nil {
-		var nsk *types.NoSuchKey
-		if errors.As(err, &nsk) {
-			return nil, nil
+		utils.Logger().Warn("disk cache get error", zap.String("key", key), zap.Error(err))
+	}
+	if buf != nil {
+		if VerifyChecksum(info, buf) {
+			return buf, nil
 		}
+		utils.Logger().Warn("disk cache checksum mismatch", zap.String("key", key))
+		s.disk.remove(context.Background(), key)
+	}
+	buf, err = s.s3GetFile(ctx, info.BlockType.String(), key)
+	if err != nil {
 		return nil, err
 	}
-	buf, err = io.ReadAll(result.Body)
+	if len(buf) > 0 {
+		s.diskPut(key, buf)
+	}
+	return buf, nil
+}
+
+// getBlockRange reads a range of a block from the caches, then from the store
+// by a range read without caching it.
+func (s *server) getBlockRange(ctx context.Context, lru *utils.LRU, req *pb.GetBlockRequest, key string) ([]byte, error) {
+	if !req.NoCache {
+		if val, ok := lru.Peek(key); ok {
+			return sliceRange(val.([]byte), req.Offset, req.Length), nil
+		}
+	}
+	if s.disk != nil {
+		buf, err := s.disk.get(context.Background(), key)
+		if err != nil {
+			utils.Logger().Warn("disk cache get error", zap.String("key", key), zap.Error(err))
+		}
+		if buf != nil && VerifyChecksum(req.Info, buf) {
+			return sliceRange(buf, req.Offset, req.Length), nil
+		}
+	}
+	timeStart := time.Now()
+	buf, err := s.reads.read(ctx, "range/"+req.Info.BlockType.String(), func(ctx context.Context) ([]byte, error) {
+		return s.store.GetRange(ctx, key, req.Offset, req.Length)
+	})
 	if err != nil {
 		return nil, err
 	}
-	s.s3Metric.ObserveReadLatency(BucketName, float64(time.Since(timeStart).Milliseconds()))
-	s.s3Metric.IncreaseReadSize(BucketName, int64(len(buf)))
+	s.s3Metric.ObserveReadLatency(s.store.Name(), float64(time.Since(timeStart).Milliseconds()))
+	s.s3Metric.IncreaseReadSize(s.store.Name(), int64(len(buf)))
 	return buf, nil
 }
 
+func (s *server) diskPut(key string, buf []byte) {
+	if s.disk == nil {
+		return
+	}
+	if err := s.disk.put(context.Background(), key, buf); err != nil {
+		utils.Logger().Warn("disk cache put error", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// getSharedBlockFile reads a block through lru, concurrent requests of it
+// share one store read. The read runs on its own deadline so a canceled
+// request only stops waiting for it.
+func (s *server) getSharedBlockFile(ctx context.Context, lru *utils.LRU, info *pb.BlockInfo, key string) ([]byte, error) {
+	type result struct {
+		buf []byte
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		val, err := lru.Get(key, info.BlockNum, func() (interface{}, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), SharedReadTimeout)
+			defer cancel()
+			return s.getBlockFile(ctx, info, key)
+		})
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		results <- result{buf: val.([]byte)}
+	}()
+	select {
+	case res := <-results:
+		return res.buf, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (s *server) GetBlock(req *pb.GetBlockRequest, client pb.S3Proxy_GetBlockServer) error {
+	// store reads not shared with other requests end with the request.
+	ctx := client.Context()
 	commonPrefix := utils.CommonPrefix(req.Info.Env, req.Info.ChainId, req.Info.Role, req.Info.BlockType)
 	lru := s.cache.GetOrCreatePrefixCache(commonPrefix)
 	key := utils.InfoToPrefix(req.Info)
 	var buf []byte
-	if req.NoCache {
-		val, err := s.s3GetFile(key)
+	if req.Offset > 0 || req.Length > 0 {
+		val, err := s.getBlockRange(ctx, lru, req, key)
+		if err != nil {
+			return status.Errorf(utils.AwsS3ErrorCode, "GetBlock failed, err : %v", err)
+		}
+		buf = val
+	} else if req.NoCache {
+		val, err := s.getBlockFile(ctx, req.Info, key)
 		if err != nil {
 			return status.Errorf(utils.AwsS3ErrorCode, "GetBlock failed, err : %v", err)
 		}
 		buf = val
 	} else {
-		val, err := lru.Get(key, req.Info.BlockNum, func() (interface{}, error) { return s.s3GetFile(key) })
+		val, err := s.getSharedBlockFile(ctx, lru, req.Info, key)
 		if err != nil {
 			return status.Errorf(utils.AwsS3ErrorCode, "GetBlock failed, err : %v", err)
 		}
-		buf = val.([]byte)
+		buf = val
 	}
 	chunk := s.getPool.Get().(*[]byte)
 	defer s.getPool.Put(chunk)
@@ -136,16 +258,38 @@ func (s *server) GetBlock(req *pb.GetBlockRequest, client pb.S3Proxy_GetBlockSer
 	return nil
 }
 
-func (s *server) PutBlock(client pb.S3Proxy_PutBlockServer) error {
+// PutBlock streams the chunks of a block to the store holding at most
+// PartSize bytes, the upload is aborted on errors. The info of the first chunk
+// names the block, a client streaming it sends its checksum and size in a
+// last info. Only blocks of at most PartSize bytes are cached.
+func (s *server) PutBlock(client pb.S3Proxy_PutBlockServer) (err error) {
 	chunk, err := client.Recv()
 	if err != nil {
 		return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, err : %v", err)
 	}
-	buf := make([]byte, int(chunk.Info.BlockSize))
-	buf = buf[:0]
-
-	buffer := bytes.NewBuffer(buf)
 	info := chunk.Info
+	cacheOnly := chunk.CacheOnly
+	commonPrefix := utils.CommonPrefix(info.Env, info.ChainId, info.Role, info.BlockType)
+	key := utils.InfoToPrefix(info)
+	var writer ObjectWriter
+	if !cacheOnly {
+		writer, err = s.store.NewWriter(context.Background(), key)
+		if err != nil {
+			return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, err : %v", err)
+		}
+		defer func() {
+			if writer == nil {
+				return
+			}
+			if abortErr := writer.Abort(); abortErr != nil {
+				utils.Logger().Error("PutBlock abort error", zap.String("key", key), zap.Error(abortErr))
+			}
+		}()
+	}
+	timeStart := time.Now()
+	buffer := &bytes.Buffer{}
+	hash := newChecksumHash()
+	bodySize := 0
 	for {
 		chunk, err := client.Recv()
 		if err != nil {
@@ -154,33 +298,102 @@ func (s *server) PutBlock(client pb.S3Proxy_PutBlockServer) error {
 			}
 			return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, err : %v", err)
 		}
-		if len(chunk.Chunk) > 0 {
+		if chunk.Info != nil {
+			if utils.InfoToPrefix(chunk.Info) != key {
+				return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, info of another block, key : %s", key)
+			}
+			info = chunk.Info
+		}
+		if len(chunk.Chunk) == 0 {
+			continue
+		}
+		hash.Write(chunk.Chunk)
+		bodySize += len(chunk.Chunk)
+		if buffer != nil && bodySize > PartSize {
+			buffer = nil
+		}
+		if buffer != nil {
 			buffer.Write(chunk.Chunk)
 		}
+		if writer != nil {
+			_, err = writer.Write(chunk.Chunk)
+			if err != nil {
+				return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, err : %v", err)
+			}
+		}
 	}
-	commonPrefix := utils.CommonPrefix(info.Env, info.ChainId, info.Role, info.BlockType)
-	key := utils.InfoToPrefix(info)
-	timeStart := time.Now()
-	bodySize := len(buffer.Bytes())
-	rsp, err := s.s3.PutObject(context.Background(), &s3.PutObjectInput{
-		Bucket: aws.String(BucketName),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(buffer.Bytes()),
-	})
-	if err != nil {
-		return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, err : %v", err)
+	if expected := GetChecksum(info); len(expected) > 0 && !bytes.Equal(expected, hash.Sum(nil)) {
+		s.s3Metric.IncreaseChecksumMismatch(s.store.Name())
+		utils.Logger().Error("PutBlock checksum mismatch", zap.String("key", key), zap.Int("size", bodySize))
+		return status.Errorf(utils.ChecksumMismatchErrorCode, "PutBlock failed, checksum mismatch, key : %s", key)
+	}
+	if writer != nil {
+		err = writer.Close()
+		if err != nil {
+			return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, err : %v", err)
+		}
+		writer = nil
+		s.s3Metric.ObserveWriteLatency(s.store.Name(), float64(time.Since(timeStart).Milliseconds()))
+		s.s3Metric.IncreaseWriteSize(s.store.Name(), int64(bodySize))
+		if info.BlockType == pb.BlockInfo_HEADER {
+			err = s.index.add(context.Background(), info)
+			if err != nil {
+				utils.Logger().Error("PutBlock index error", zap.String("key", key), zap.Error(err))
+				return status.Errorf(utils.AwsS3ErrorCode, "PutBlock failed, index err : %v", err)
+			}
+		}
+		utils.Logger().Info("PutBlock", zap.String("key", key), zap.Int("size", bodySize))
+	}
+	if buffer != nil {
+		lru := s.cache.GetOrCreatePrefixCache(commonPrefix)
+		lru.Insert(key, buffer.Bytes(), info.BlockNum)
+		s.diskPut(key, buffer.Bytes())
+		s.publish(info, buffer.Bytes())
 	}
-	s.s3Metric.ObserveWriteLatency(BucketName, float64(time.Since(timeStart).Milliseconds()))
-	s.s3Metric.IncreaseWriteSize(BucketName, int64(bodySize))
-	utils.Logger().Info("PutBlock", zap.String("key", key), zap.Any("rsp", rsp))
-	lru := s.cache.GetOrCreatePrefixCache(commonPrefix)
-	lru.Insert(key, buffer.Bytes(), info.BlockNum)
 	client.SendAndClose(&pb.PutBlockReply{})
 	return nil
 }
 
+// publish pushes a put block to the watchers of its chain.
+func (s *server) publish(info *pb.BlockInfo, buf []byte) {
+	s.RLock()
+	watcher, ok := s.watchers[utils.TopicPrefix(info.Env, info.ChainId, info.Role)]
+	s.RUnlock()
+	if ok {
+		watcher.Publish(&pb.WatchBlocksReply{Info: info, Data: buf})
+	}
+}
+
+// WatchBlocks streams the blocks put through the proxy from now on. A watcher
+// lagging behind by broker.MaxChannelSize blocks is disconnected.
+func (s *server) WatchBlocks(req *pb.WatchBlocksRequest, stream pb.S3Proxy_WatchBlocksServer) error {
+	prefix := utils.TopicPrefix(req.Env, req.ChainId, req.Role)
+	s.Lock()
+	watcher, ok := s.watchers[prefix]
+	if !ok {
+		watcher = broker.NewBroker[pb.WatchBlocksReply]()
+		s.watchers[prefix] = watcher
+	}
+	s.Unlock()
+	ch := watcher.Subscribe()
+	defer watcher.Unsubscribe(ch)
+	for {
+		select {
+		case rsp, ok := <-ch:
+			if !ok {
+				return status.Errorf(utils.WatcherTooSlowErrorCode, "WatchBlocks failed, watcher too slow, prefix : %s", prefix)
+			}
+			if err := stream.Send(rsp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
 func (s *server) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFileReply, error) {
-	buf, err := s.s3GetFile(req.Path)
+	buf, err := s.s3GetFile(ctx, "file", req.Path)
 	if err != nil {
 		return nil, status.Errorf(utils.AwsS3ErrorCode, "GetFile failed, err : %v", err)
 	}
@@ -190,46 +403,68 @@ func (s *server) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFi
 func (s *server) PutFile(ctx context.Context, req *pb.PutFileRequest) (*pb.PutFileReply, error) {
 	bodySize := len(req.Data)
 	timeStart := time.Now()
-	_, err := s.s3.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(BucketName),
-		Key:    aws.String(req.Path),
-		Body:   bytes.NewReader(req.Data),
-	})
+	err := s.store.Put(ctx, req.Path, req.Data)
 	if err != nil {
 		return nil, status.Errorf(utils.AwsS3ErrorCode, "PutFile failed, err : %v", err)
 	}
 	utils.Logger().Info("PutFile", zap.String("key", req.Path), zap.Any("err", err))
-	s.s3Metric.ObserveWriteLatency(BucketName, float64(time.Since(timeStart).Milliseconds()))
-	s.s3Metric.IncreaseWriteSize(BucketName, int64(bodySize))
+	s.s3Metric.ObserveWriteLatency(s.store.Name(), float64(time.Since(timeStart).Milliseconds()))
+	s.s3Metric.IncreaseWriteSize(s.store.Name(), int64(bodySize))
 	return &pb.PutFileReply{}, nil
 }
 
 func (s *server) ListHeaderStartAt(ctx context.Context, req *pb.ListHeaderStartAtRequest) (*pb.ListHeaderStartAtReply, error) {
-	prefix := utils.CommonPrefix(req.Env, req.ChainId, req.Role, pb.BlockInfo_HEADER)
-	result, err := s.s3.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket:     aws.String(BucketName),
-		Prefix:     aws.String(prefix),
-		MaxKeys:    int32(req.CountNum),
-		StartAfter: aws.String(fmt.Sprintf("%s/%012d", prefix, req.BlockNum)),
+	rsp := &pb.ListHeaderStartAtReply{}
+	count := int64(0)
+	err := s.index.list(ctx, req.Env, req.ChainId, req.Role, req.BlockNum, func(info *pb.BlockInfo) bool {
+		if info.MsgOffset > req.AfterMsgOffset {
+			rsp.Infos = append(rsp.Infos, info)
+		}
+		count++
+		return req.CountNum <= 0 || count < req.CountNum
 	})
 	if err != nil {
-		return nil, err
+		utils.Logger().Error("ListHeaderStartAt failed", zap.Error(err))
+		return nil, status.Errorf(utils.AwsS3ErrorCode, "ListHeaderStartAt failed, err : %v", err)
 	}
-	rsp := &pb.ListHeaderStartAtReply{}
-	for _, object := range result.Contents {
-		info, err := utils.PrefixToHeaderInfo(*object.Key)
-		if err != nil {
-			utils.Logger().Error("ListHeaderStartAt failed", zap.String("key", *object.Key))
-			return nil, status.Errorf(utils.ReadInvalidHeaderErrorCode, "ListHeaderStartAt failed, key : %s", *object.Key)
+	return rsp, nil
+}
+
+// ListHeaders streams the headers from StartBlockNum to EndBlockNum in pages
+// of ListPageSize headers.
+func (s *server) ListHeaders(req *pb.ListHeadersRequest, stream pb.S3Proxy_ListHeadersServer) error {
+	rsp := &pb.ListHeadersReply{}
+	var sendErr error
+	err := s.index.list(stream.Context(), req.Env, req.ChainId, req.Role, req.StartBlockNum, func(info *pb.BlockInfo) bool {
+		if req.EndBlockNum > 0 && info.BlockNum >= req.EndBlockNum {
+			return false
 		}
-		if int64(info.MsgOffset) > req.AfterMsgOffset {
+		if info.MsgOffset > req.AfterMsgOffset {
 			rsp.Infos = append(rsp.Infos, info)
 		}
+		if len(rsp.Infos) < ListPageSize {
+			return true
+		}
+		sendErr = stream.Send(rsp)
+		rsp = &pb.ListHeadersReply{}
+		return sendErr == nil
+	})
+	if err != nil {
+		utils.Logger().Error("ListHeaders failed", zap.Error(err))
+		return status.Errorf(utils.AwsS3ErrorCode, "ListHeaders failed, err : %v", err)
 	}
-	return rsp, nil
+	if sendErr != nil {
+		return sendErr
+	}
+	if len(rsp.Infos) > 0 {
+		return stream.Send(rsp)
+	}
+	return nil
 }
 
 func (s *server) RemoveFiles(ctx context.Context, req *pb.RemoveFilesRequest) (rsp *pb.RemoveFilesReply, err error) {
+	rsp = &pb.RemoveFilesReply{}
+	var headers []*pb.BlockInfo
 	for _, info := range req.Infos {
 		key := ""
 		if info.BlockType == pb.BlockInfo_DATA {
@@ -237,14 +472,41 @@ func (s *server) RemoveFiles(ctx context.Context, req *pb.RemoveFilesRequest) (r
 		} else {
 			key = utils.HeaderPrefix(info)
 		}
-		_, err = s.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
-			Bucket: aws.String(BucketName),
-			Key:    aws.String(key),
-		})
+		stat, err := s.store.Stat(ctx, key)
 		if err != nil {
-			return nil, err
+			return nil, status.Errorf(utils.AwsS3ErrorCode, "RemoveFiles failed, err : %v", err)
+		}
+		if stat != nil && req.ModifiedBefore > 0 && stat.ModTime.Unix() >= req.ModifiedBefore {
+			rsp.Kept = append(rsp.Kept, info)
+			continue
+		}
+		// a header missing in the store may still be in the index.
+		if !req.DryRun && info.BlockType != pb.BlockInfo_DATA {
+			headers = append(headers, info)
+		}
+		if stat == nil {
+			continue
+		}
+		if !req.DryRun {
+			err = s.store.Delete(ctx, key)
+			if err != nil {
+				return nil, status.Errorf(utils.AwsS3ErrorCode, "RemoveFiles failed, err : %v", err)
+			}
+			if s.disk != nil {
+				err = s.disk.remove(ctx, key)
+				if err != nil {
+					return nil, status.Errorf(utils.AwsS3ErrorCode, "RemoveFiles failed, disk cache err : %v", err)
+				}
+			}
 		}
+		rsp.RemovedFiles++
+		rsp.RemovedBytes += stat.Size
 	}
-	return &pb.RemoveFilesReply{}, nil
-
+	if len(headers) > 0 {
+		err = s.index.remove(ctx, headers)
+		if err != nil {
+			return nil, status.Errorf(utils.AwsS3ErrorCode, "RemoveFiles failed, index err : %v", err)
+		}
+	}
+	return rsp, nil
 }
@@ -0,0 +1,99 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	DefaultBucketName = "prod-blockchain-replicator"
+	DefaultRegion     = "ap-northeast-1"
+)
+
+const (
+	StoreTypeAws    = "aws"
+	StoreTypeFs     = "fs"
+	StoreTypeMemory = "memory"
+)
+
+// BlobStore is the object storage backend of the S3 proxy.
+type BlobStore interface {
+	// Name returns the name of the store, used as the metric label.
+	Name() string
+
+	// Get returns the object stored at key, or nil if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// GetRange returns length bytes of the object stored at key from offset,
+	// up to its end if length is 0, or nil if the key does not exist.
+	GetRange(ctx context.Context, key string, offset, length int64) ([]byte, error)
+
+	// Put stores data at key, overwriting any existing object.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// List returns at most maxKeys keys with the given prefix that sort after
+	// startAfter, in lexicographical order.
+	List(ctx context.Context, prefix string, startAfter string, maxKeys int32) ([]string, error)
+
+	// Delete removes the object stored at key, missing keys are not an error.
+	Delete(ctx context.Context, key string) error
+
+	// Stat returns the size and modification time of the object stored at
+	// key, or nil if the key does not exist.
+	Stat(ctx context.Context, key string) (*ObjectStat, error)
+
+	// NewWriter streams an object to key, buffering at most PartSize bytes.
+	NewWriter(ctx context.Context, key string) (ObjectWriter, error)
+}
+
+// ObjectWriter streams an object to a BlobStore, the object is only stored
+// by Close and Abort discards it.
+type ObjectWriter interface {
+	io.Writer
+	Close() error
+	Abort() error
+}
+
+// sliceRange returns length bytes of data from offset, up to its end if length
+// is 0.
+func sliceRange(data []byte, offset, length int64) []byte {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[offset:]
+	if length > 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return data
+}
+
+// ObjectStat is the metadata of a stored object.
+type ObjectStat struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// StoreConfig represents the configuration of a BlobStore.
+type StoreConfig struct {
+	Type     string
+	Bucket   string
+	Region   string
+	Endpoint string
+	RootDir  string
+}
+
+// NewBlobStore creates a BlobStore from the config.
+func NewBlobStore(ctx context.Context, cfg *StoreConfig) (BlobStore, error) {
+	switch cfg.Type {
+	case StoreTypeAws, "":
+		return NewAwsStore(ctx, cfg.Bucket, cfg.Region, cfg.Endpoint)
+	case StoreTypeFs:
+		return NewFsStore(cfg.RootDir)
+	case StoreTypeMemory:
+		return NewMemStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown store type: %s", cfg.Type)
+	}
+}
@@ -0,0 +1,250 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// AwsStore is a BlobStore backed by an S3 compatible bucket.
+type AwsStore struct {
+	s3     *s3.Client
+	bucket string
+}
+
+// NewAwsStore creates an AwsStore, a non-empty endpoint switches to
+// path-style addressing so MinIO and other S3 compatible services work.
+func NewAwsStore(ctx context.Context, bucket, region, endpoint string) (*AwsStore, error) {
+	if bucket == "" {
+		bucket = DefaultBucketName
+	}
+	if region == "" {
+		region = DefaultRegion
+	}
+	sdkConfig, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, err
+	}
+	client := s3.NewFromConfig(sdkConfig, func(o *s3.Options) {
+		if endpoint != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	return &AwsStore{
+		s3:     client,
+		bucket: bucket,
+	}, nil
+}
+
+func (a *AwsStore) Name() string {
+	return a.bucket
+}
+
+func (a *AwsStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return a.getObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+}
+
+func (a *AwsStore) GetRange(ctx context.Context, key string, offset, length int64) ([]byte, error) {
+	rng := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		rng = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+	buf, err := a.getObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(rng),
+	})
+	if err != nil && offset > 0 {
+		// S3 rejects a range starting at the end of the object.
+		if stat, statErr := a.Stat(ctx, key); statErr == nil && stat != nil && offset >= stat.Size {
+			return []byte{}, nil
+		}
+	}
+	return buf, err
+}
+
+func (a *AwsStore) getObject(ctx context.Context, input *s3.GetObjectInput) ([]byte, error) {
+	result, err := a.s3.GetObject(ctx, input)
+	if result != nil {
+		defer result.Body.Close()
+	}
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return io.ReadAll(result.Body)
+}
+
+func (a *AwsStore) Put(ctx context.Context, key string, data []byte) error {
+	_, err := a.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+// List follows the continuation tokens of ListObjectsV2 until maxKeys keys
+// are listed, S3 returns at most 1000 keys per call.
+func (a *AwsStore) List(ctx context.Context, prefix string, startAfter string, maxKeys int32) ([]string, error) {
+	var keys []string
+	input := &s3.ListObjectsV2Input{
+		Bucket:     aws.String(a.bucket),
+		Prefix:     aws.String(prefix),
+		MaxKeys:    maxKeys,
+		StartAfter: aws.String(startAfter),
+	}
+	for {
+		result, err := a.s3.ListObjectsV2(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, *object.Key)
+		}
+		if !result.IsTruncated || (maxKeys > 0 && len(keys) >= int(maxKeys)) {
+			break
+		}
+		input.ContinuationToken = result.NextContinuationToken
+		if maxKeys > 0 {
+			input.MaxKeys = maxKeys - int32(len(keys))
+		}
+	}
+	if maxKeys > 0 && len(keys) > int(maxKeys) {
+		keys = keys[:maxKeys]
+	}
+	return keys, nil
+}
+
+func (a *AwsStore) Stat(ctx context.Context, key string) (*ObjectStat, error) {
+	result, err := a.s3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	stat := &ObjectStat{Size: result.ContentLength}
+	if result.LastModified != nil {
+		stat.ModTime = *result.LastModified
+	}
+	return stat, nil
+}
+
+func (a *AwsStore) Delete(ctx context.Context, key string) error {
+	_, err := a.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// awsWriter uploads the parts of an object of more than PartSize bytes by a
+// multipart upload, smaller objects are put at once.
+type awsWriter struct {
+	ctx      context.Context
+	store    *AwsStore
+	key      string
+	buf      []byte
+	uploadId *string
+	parts    []types.CompletedPart
+}
+
+func (a *AwsStore) NewWriter(ctx context.Context, key string) (ObjectWriter, error) {
+	return &awsWriter{ctx: ctx, store: a, key: key}, nil
+}
+
+func (w *awsWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		m := PartSize - len(w.buf)
+		if m > len(p) {
+			m = len(p)
+		}
+		w.buf = append(w.buf, p[:m]...)
+		p = p[m:]
+		n += m
+		if len(w.buf) == PartSize {
+			if err := w.upload(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (w *awsWriter) upload() error {
+	if w.uploadId == nil {
+		result, err := w.store.s3.CreateMultipartUpload(w.ctx, &s3.CreateMultipartUploadInput{
+			Bucket: aws.String(w.store.bucket),
+			Key:    aws.String(w.key),
+		})
+		if err != nil {
+			return err
+		}
+		w.uploadId = result.UploadId
+	}
+	partNumber := int32(len(w.parts) + 1)
+	result, err := w.store.s3.UploadPart(w.ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(w.store.bucket),
+		Key:           aws.String(w.key),
+		UploadId:      w.uploadId,
+		PartNumber:    partNumber,
+		Body:          bytes.NewReader(w.buf),
+		ContentLength: int64(len(w.buf)),
+	})
+	if err != nil {
+		return err
+	}
+	w.parts = append(w.parts, types.CompletedPart{ETag: result.ETag, PartNumber: partNumber})
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *awsWriter) Close() error {
+	if w.uploadId == nil {
+		return w.store.Put(w.ctx, w.key, w.buf)
+	}
+	if len(w.buf) > 0 {
+		if err := w.upload(); err != nil {
+			return err
+		}
+	}
+	_, err := w.store.s3.CompleteMultipartUpload(w.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(w.store.bucket),
+		Key:             aws.String(w.key),
+		UploadId:        w.uploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: w.parts},
+	})
+	return err
+}
+
+func (w *awsWriter) Abort() error {
+	w.buf = nil
+	if w.uploadId == nil {
+		return nil
+	}
+	_, err := w.store.s3.AbortMultipartUpload(w.ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(w.store.bucket),
+		Key:      aws.String(w.key),
+		UploadId: w.uploadId,
+	})
+	return err
+}
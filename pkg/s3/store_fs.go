@@ -0,0 +1,209 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FsStore is a BlobStore rooted at a local directory, each key is stored as
+// a file at the same relative path.
+type FsStore struct {
+	root string
+}
+
+// NewFsStore creates a FsStore rooted at dir, creating it if needed.
+func NewFsStore(dir string) (*FsStore, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &FsStore{root: root}, nil
+}
+
+func (f *FsStore) Name() string {
+	return f.root
+}
+
+func (f *FsStore) keyPath(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid key: %s", key)
+	}
+	return filepath.Join(f.root, filepath.FromSlash(clean)), nil
+}
+
+func (f *FsStore) Get(ctx context.Context, key string) ([]byte, error) {
+	p, err := f.keyPath(key)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (f *FsStore) GetRange(ctx context.Context, key string, offset, length int64) ([]byte, error) {
+	p, err := f.keyPath(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var r io.Reader = file
+	if length > 0 {
+		r = io.LimitReader(file, length)
+	}
+	return io.ReadAll(r)
+}
+
+func (f *FsStore) Put(ctx context.Context, key string, data []byte) error {
+	p, err := f.keyPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// write to a temp file first so readers never see a partial object.
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (f *FsStore) List(ctx context.Context, prefix string, startAfter string, maxKeys int32) ([]string, error) {
+	dir := f.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = filepath.Join(f.root, filepath.FromSlash(prefix[:i]))
+	}
+	var keys []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(f.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	if maxKeys > 0 && len(keys) > int(maxKeys) {
+		keys = keys[:maxKeys]
+	}
+	return keys, nil
+}
+
+func (f *FsStore) Stat(ctx context.Context, key string) (*ObjectStat, error) {
+	p, err := f.keyPath(key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ObjectStat{Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+func (f *FsStore) Delete(ctx context.Context, key string) error {
+	p, err := f.keyPath(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+type fsWriter struct {
+	*os.File
+	path string
+}
+
+func (f *FsStore) NewWriter(ctx context.Context, key string) (ObjectWriter, error) {
+	p, err := f.keyPath(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &fsWriter{File: tmp, path: p}, nil
+}
+
+func (w *fsWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.Name())
+		return err
+	}
+	if err := os.Rename(w.Name(), w.path); err != nil {
+		os.Remove(w.Name())
+		return err
+	}
+	return nil
+}
+
+func (w *fsWriter) Abort() error {
+	w.File.Close()
+	err := os.Remove(w.Name())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemStore is an in-memory BlobStore, mostly useful for tests.
+type MemStore struct {
+	sync.RWMutex
+	objects  map[string][]byte
+	modTimes map[string]time.Time
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{
+		objects:  make(map[string][]byte),
+		modTimes: make(map[string]time.Time),
+	}
+}
+
+func (m *MemStore) Name() string {
+	return StoreTypeMemory
+}
+
+func (m *MemStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.RLock()
+	defer m.RUnlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, nil
+	}
+	buf := make([]byte, len(data))
+	copy(buf, data)
+	return buf, nil
+}
+
+func (m *MemStore) GetRange(ctx context.Context, key string, offset, length int64) ([]byte, error) {
+	m.RLock()
+	defer m.RUnlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, nil
+	}
+	data = sliceRange(data, offset, length)
+	buf := make([]byte, len(data))
+	copy(buf, data)
+	return buf, nil
+}
+
+func (m *MemStore) Put(ctx context.Context, key string, data []byte) error {
+	buf := make([]byte, len(data))
+	copy(buf, data)
+	m.Lock()
+	defer m.Unlock()
+	m.objects[key] = buf
+	m.modTimes[key] = time.Now()
+	return nil
+}
+
+func (m *MemStore) List(ctx context.Context, prefix string, startAfter string, maxKeys int32) ([]string, error) {
+	m.RLock()
+	defer m.RUnlock()
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if maxKeys > 0 && len(keys) > int(maxKeys) {
+		keys = keys[:maxKeys]
+	}
+	return keys, nil
+}
+
+func (m *MemStore) Delete(ctx context.Context, key string) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.objects, key)
+	delete(m.modTimes, key)
+	return nil
+}
+
+func (m *MemStore) Stat(ctx context.Context, key string) (*ObjectStat, error) {
+	m.RLock()
+	defer m.RUnlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, nil
+	}
+	return &ObjectStat{Size: int64(len(data)), ModTime: m.modTimes[key]}, nil
+}
+
+type memWriter struct {
+	bytes.Buffer
+	ctx   context.Context
+	store *MemStore
+	key   string
+}
+
+func (m *MemStore) NewWriter(ctx context.Context, key string) (ObjectWriter, error) {
+	return &memWriter{ctx: ctx, store: m, key: key}, nil
+}
+
+func (w *memWriter) Close() error {
+	return w.store.Put(w.ctx, w.key, w.Bytes())
+}
+
+func (w *memWriter) Abort() error {
+	w.Reset()
+	return nil
+}
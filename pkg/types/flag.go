@@ -54,3 +54,37 @@ type FailoverFlag struct {
 	Role       int    `type:"int" shorthand:"r" enable-env:"true" usage:"role 1 master, 2 backup" json:"role"`
 	GrpcServer string `type:"string" shorthand:"g" enable-env:"true" usage:"address of grpc server" json:"grpc_server"`
 }
+
+// RewindFlag is the flag for rewind tool
+type RewindFlag struct {
+	BlockNum   int    `type:"int" shorthand:"n" enable-env:"true" usage:"block number to rewind to" json:"block_num"`
+	GrpcServer string `type:"string" shorthand:"g" enable-env:"true" usage:"address of reader remote server" json:"grpc_server"`
+}
+
+// RestoreFlag is the flag for restore tool
+type RestoreFlag struct {
+	S3ProxyAddr string `type:"string" shorthand:"s" enable-env:"true" usage:"comma separated addresses of s3 proxies" json:"s3proxy_addr"`
+	DBInfoPath  string `type:"string" shorthand:"f" enable-env:"true" usage:"db info of the paths to restore to" json:"db_info_path"`
+	Manifest    string `type:"string" shorthand:"m" enable-env:"true" usage:"manifest key of the checkpoint, the latest one of the name if empty" json:"manifest"`
+	Env         string `type:"string" shorthand:"e" enable-env:"true" usage:"environment" json:"env"`
+	ChainID     string `type:"string" shorthand:"i" enable-env:"true" usage:"chain id" json:"chain_id"`
+	Role        string `type:"string" shorthand:"r" enable-env:"true" usage:"role master or backup" json:"role"`
+	Name        string `type:"string" shorthand:"n" enable-env:"true" usage:"checkpoint name" json:"name"`
+	CacheSize   int    `type:"int" shorthand:"z" enable-env:"true" usage:"db cache size in MB" json:"cache_size"`
+}
+
+// GCFlag is the flag for gc tool
+type GCFlag struct {
+	S3ProxyAddr  string `type:"string" shorthand:"s" enable-env:"true" usage:"comma separated addresses of s3 proxies" json:"s3proxy_addr"`
+	GrpcServer   string `type:"string" shorthand:"g" enable-env:"true" usage:"address of ndrc grpc server listing the readers" json:"grpc_server"`
+	Env          string `type:"string" shorthand:"e" enable-env:"true" usage:"environment" json:"env"`
+	ChainID      string `type:"string" shorthand:"i" enable-env:"true" usage:"chain id" json:"chain_id"`
+	Roles        string `type:"string" shorthand:"r" enable-env:"true" usage:"comma separated roles, master,backup if empty" json:"roles"`
+	KeepBlocks   int    `type:"int" shorthand:"k" enable-env:"true" usage:"number of blocks kept below the highest reader of a role" json:"keep_blocks"`
+	ReorgWindow  int    `type:"int" shorthand:"u" enable-env:"true" usage:"number of blocks kept below the lowest reader of a role to roll back, at least its reorg_deep and undo_window" json:"reorg_window"`
+	KeepWindow   int    `type:"int" shorthand:"w" enable-env:"true" usage:"seconds objects are kept after their upload" json:"keep_window"`
+	Interval     int    `type:"int" shorthand:"n" enable-env:"true" usage:"seconds between collections, collect once if 0" json:"interval"`
+	DryRun       bool   `type:"bool" shorthand:"y" enable-env:"true" usage:"only report what would be removed" json:"dry_run"`
+	Checkpoints  string `type:"string" shorthand:"p" enable-env:"true" usage:"comma separated names of the reader checkpoints kept, default if empty" json:"checkpoints"`
+	MetricListen string `type:"string" shorthand:"m" enable-env:"true" usage:"listen address of the metrics server, none if empty" json:"metric_listen"`
+}
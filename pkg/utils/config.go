@@ -11,6 +11,7 @@ import (
 type Config struct {
 	S3ProxyAddr      string
 	KafkaAddr        string
+	KafkaPartition   int
 	RemoteAddr       string
 	RemoteListenAddr string
 	Env              string
@@ -21,22 +22,57 @@ type Config struct {
 	DBCacheSize      int
 	NdrcAddr         string
 	MetricEndpoint   string
+	// Compression is the codec of blocks uploaded to s3, one of none, zstd or snappy.
+	Compression string
+	// PrefetchDepth is the number of blocks the reader downloads ahead of application.
+	PrefetchDepth int
+	// UndoWindow is the number of latest blocks the reader can rewind, at least ReorgDeep.
+	UndoWindow int
+	// KafkaGroupId is the consumer group the reader commits its applied offset to, empty disables it.
+	KafkaGroupId string
+	// KafkaOffsetMaxDiff is the max difference between the meta db and the consumer group offsets on startup.
+	KafkaOffsetMaxDiff int
+	// HeaderBus is the message bus of block infos, one of kafka, redis or memory, kafka by default.
+	HeaderBus string
+	// RedisAddr is the address of the redis server of the redis HeaderBus.
+	RedisAddr string
+	// Discovery is how the reader discovers new blocks, kafka uses the HeaderBus, s3 lists
+	// the headers in s3 and auto lists them while the HeaderBus is unavailable.
+	Discovery string
+	// SnapshotInterval is the number of blocks between snapshots of the DBs exported by the writer, 0 disables them.
+	SnapshotInterval int
+	// CheckpointInterval is the number of seconds between checkpoints of the DBs exported by the reader, 0 disables them.
+	CheckpointInterval int
+	// CheckpointName names the checkpoints of the reader, several readers of a chain need distinct names.
+	CheckpointName string
+	// S3ProxyReplicas is the number of proxies following the owner of a block whose caches the writer warms.
+	S3ProxyReplicas int
+	// WatchBlocks makes the reader watch the blocks pushed by the s3 proxies and apply them without downloading them.
+	WatchBlocks bool
+	// SigningKey is the file of the base64 ed25519 private key the writer signs blocks with, empty disables signing.
+	SigningKey string
+	// TrustedKeys are the comma separated base64 ed25519 public keys of the writers, the reader
+	// rejects unsigned or mis-signed blocks if set.
+	TrustedKeys string
 }
 
 // NewDevelopmentConfig returns a Dev env Config with default values.
 func NewDevelopmentConfig() *Config {
 	return &Config{
-		S3ProxyAddr:      "127.0.0.1:8765",
-		KafkaAddr:        "127.0.0.1:9092",
-		RemoteAddr:       "127.0.0.1:7654",
-		RemoteListenAddr: "0.0.0.0:7654",
-		Env:              "test",
-		ChainId:          "256",
-		Role:             "master",
-		DBInfoPath:       "dbinfo.json",
-		ReorgDeep:        128,
-		DBCacheSize:      1 << 32,
-		NdrcAddr:         "127.0.0.1:8089",
+		S3ProxyAddr:        "127.0.0.1:8765",
+		KafkaAddr:          "127.0.0.1:9092",
+		RemoteAddr:         "127.0.0.1:7654",
+		RemoteListenAddr:   "0.0.0.0:7654",
+		Env:                "test",
+		ChainId:            "256",
+		Role:               "master",
+		DBInfoPath:         "dbinfo.json",
+		ReorgDeep:          128,
+		DBCacheSize:        1 << 32,
+		NdrcAddr:           "127.0.0.1:8089",
+		PrefetchDepth:      16,
+		UndoWindow:         128,
+		KafkaOffsetMaxDiff: 1024,
 	}
 }
 
@@ -53,3 +89,28 @@ func OpenAndReadDbInfo(path string) (*pb.DBInfoList, error) {
 	}
 	return dbInfo, nil
 }
+
+// LoadChainConfigs reads a JSON array of per chain overrides of base from
+// path, e.g. [{"ChainId": "eth", "RemoteListenAddr": "0.0.0.0:8654"}], so
+// one process can host the readers of several chains.
+func LoadChainConfigs(base *Config, path string) ([]*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var overrides []json.RawMessage
+	err = json.NewDecoder(f).Decode(&overrides)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]*Config, 0, len(overrides))
+	for _, override := range overrides {
+		config := *base
+		if err := json.Unmarshal(override, &config); err != nil {
+			return nil, err
+		}
+		configs = append(configs, &config)
+	}
+	return configs, nil
+}
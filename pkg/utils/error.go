@@ -14,6 +14,26 @@ var (
 	ErrWriterRecovey = New(WriterRecoveryErrorCode, "writer recovery error")
 
 	ErrStreamNotInit = New(StreamNotInitErrorCode, "stream not init")
+
+	ErrChecksumMismatch = New(ChecksumMismatchErrorCode, "checksum mismatch")
+
+	ErrReorgTooDeep = New(ReorgTooDeepErrorCode, "reorg too deep")
+
+	ErrOffsetDiverged = New(OffsetDivergedErrorCode, "meta db offset diverged from kafka consumer group offset")
+
+	ErrHeaderNotFound = New(HeaderNotFoundErrorCode, "header not found")
+
+	ErrWatcherTooSlow = New(WatcherTooSlowErrorCode, "watcher too slow")
+
+	ErrUnknownKey = New(UnknownKeyErrorCode, "unknown encryption key")
+
+	ErrDecryptFailed = New(DecryptFailedErrorCode, "decrypt failed")
+
+	ErrBadSignature = New(BadSignatureErrorCode, "bad block signature")
+
+	ErrNoSnapshot = New(NoSnapshotErrorCode, "no snapshot")
+
+	ErrInvalidConfig = New(InvalidConfigErrorCode, "invalid config")
 )
 
 const (
@@ -27,6 +47,16 @@ const (
 	MetaDBAlreadyRegisteredErrorCode = 41007
 	BroadcasterErrorCode             = 41008
 	StreamNotInitErrorCode           = 41009
+	ChecksumMismatchErrorCode        = 41010
+	ReorgTooDeepErrorCode            = 41011
+	OffsetDivergedErrorCode          = 41012
+	HeaderNotFoundErrorCode          = 41013
+	WatcherTooSlowErrorCode          = 41014
+	UnknownKeyErrorCode              = 41015
+	DecryptFailedErrorCode           = 41016
+	BadSignatureErrorCode            = 41017
+	NoSnapshotErrorCode              = 41018
+	InvalidConfigErrorCode           = 41019
 )
 
 func New(code int, text string) error {
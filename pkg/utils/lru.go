@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"container/list"
 	"sync"
 
+	"github.com/DeBankDeFi/nodex/pkg/metrics"
 	btree "github.com/google/btree"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -13,42 +16,88 @@ const (
 	Failed    int = 3
 )
 
+// Cache is a set of LRUs by prefix sharing a memory budget. Each LRU keeps at
+// most cacheSize entries and evicts the lowest heights first, the Cache keeps
+// at most maxBytes bytes of values and evicts the least recently used ones.
 type Cache struct {
+	sync.Mutex
+	name      string
 	caches    map[string]*LRU
 	cacheSize uint
-	sync.RWMutex
+	maxBytes  int64
+	bytes     int64
+	recency   *list.List // of *entry, the most recently used first
+	metrics   *metrics.CacheMetrics
 }
 
-func NewCache(cacheSize uint) *Cache {
+// NewCache creates a Cache named name in the metrics, a zero cacheSize or
+// maxBytes disables the bound.
+func NewCache(name string, cacheSize uint, maxBytes int64) *Cache {
 	return &Cache{
+		name:      name,
 		caches:    make(map[string]*LRU),
 		cacheSize: cacheSize,
+		maxBytes:  maxBytes,
+		recency:   list.New(),
+		metrics:   metrics.NewCacheMetrics(),
 	}
 }
 
 func (c *Cache) GetOrCreatePrefixCache(prefix string) (lru *LRU) {
-	c.RLock()
+	c.Lock()
+	defer c.Unlock()
 	if lru, ok := c.caches[prefix]; ok {
-		c.RUnlock()
 		return lru
 	}
-	c.RUnlock()
-	lru = NewLru(c.cacheSize)
-	c.Lock()
-	if _, ok := c.caches[prefix]; !ok {
-		c.caches[prefix] = lru
-	} else {
-		lru = c.caches[prefix]
+	lru = &LRU{
+		cache:   c,
+		maxSize: c.cacheSize,
+		tree:    btree.NewG(32, Less),
+		items:   make(map[string]*entry),
 	}
-	c.Unlock()
+	c.caches[prefix] = lru
 	return lru
 }
 
+// Bytes returns the bytes of the values in the cache.
+func (c *Cache) Bytes() int64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.bytes
+}
+
+// evict removes the least recently used entries until the cache fits its
+// budget, c must be locked.
+func (c *Cache) evict() {
+	for c.maxBytes > 0 && c.bytes > c.maxBytes {
+		elem := c.recency.Back()
+		if elem == nil {
+			break
+		}
+		item := elem.Value.(*entry)
+		item.lru.remove(item)
+		c.metrics.IncreaseEvictions(c.name, "bytes")
+	}
+	c.metrics.SetBytes(c.name, c.bytes)
+}
+
+// ValueSize returns the size in bytes of a cached value.
+func ValueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case []byte:
+		return int64(len(v))
+	case proto.Message:
+		return int64(proto.Size(v))
+	default:
+		return 0
+	}
+}
+
 type FetchFn func() (value interface{}, err error)
 
-// LRU implements a non-thread safe fixed size/weight LRU cache
+// LRU is the cache of a prefix of a Cache, it is locked by the Cache.
 type LRU struct {
-	sync.RWMutex
+	cache   *Cache
 	maxSize uint
 	tree    *btree.BTreeG[*entry]
 	items   map[string]*entry
@@ -57,105 +106,158 @@ type LRU struct {
 // entry is used to hold a value in the evictList
 type entry struct {
 	key    string
+	lru    *LRU
 	value  interface{}
+	err    error
 	height int64
-	cond   *sync.Cond
+	size   int64
 	state  int
+	elem   *list.Element // in the recency list once fetched
+	done   chan struct{} // closed once fetched
 }
 
 func Less(item *entry, than *entry) bool {
-	return item.height < than.height
+	if item.height != than.height {
+		return item.height < than.height
+	}
+	return item.key < than.key
 }
 
-func (item *entry) Value(fetchFn FetchFn) (value interface{}, err error) {
-	if item.state == Sucess {
-		return item.value, nil
+// remove drops item from the cache, l.cache must be locked.
+func (l *LRU) remove(item *entry) {
+	if l.items[item.key] != item {
+		return
 	}
-	item.cond.L.Lock()
-	for item.state == InProcess {
-		item.cond.Wait()
+	delete(l.items, item.key)
+	l.tree.Delete(item)
+	if item.elem != nil {
+		l.cache.recency.Remove(item.elem)
+		item.elem = nil
+		l.cache.bytes -= item.size
+		l.cache.metrics.SetBytes(l.cache.name, l.cache.bytes)
 	}
-	if item.state == Sucess {
-		item.cond.L.Unlock()
-		return item.value, nil
-	} else {
-		item.state = InProcess
-		item.cond.L.Unlock()
-		value, err = fetchFn()
-		item.cond.L.Lock()
-		if err != nil {
-			item.state = Failed
-			item.cond.L.Unlock()
-			item.cond.Signal()
-			return nil, err
-		} else {
-			item.value = value
-			item.state = Sucess
-			item.cond.L.Unlock()
-			item.cond.Broadcast()
-			return item.value, nil
+}
+
+// add indexes a new item evicting the lowest height beyond maxSize, l.cache
+// must be locked.
+func (l *LRU) add(item *entry) {
+	l.items[item.key] = item
+	l.tree.ReplaceOrInsert(item)
+	for l.maxSize > 0 && l.tree.Len() > int(l.maxSize) {
+		min, ok := l.tree.Min()
+		if !ok {
+			break
 		}
+		l.remove(min)
+		l.cache.metrics.IncreaseEvictions(l.cache.name, "height")
 	}
 }
 
-// NewLru creates a weighted LRU of the given size.
-func NewLru(maxSize uint) *LRU {
-	c := &LRU{
-		maxSize: maxSize,
-		tree:    btree.NewG(32, Less),
-		items:   make(map[string]*entry, maxSize),
+// fill sets the value of an indexed item, l.cache must be locked.
+func (l *LRU) fill(item *entry, value interface{}) {
+	if item.elem != nil {
+		l.cache.bytes -= item.size
+		l.cache.recency.Remove(item.elem)
 	}
-	return c
+	item.value = value
+	item.state = Sucess
+	item.size = ValueSize(value)
+	item.elem = l.cache.recency.PushFront(item)
+	l.cache.bytes += item.size
+	l.cache.evict()
 }
 
-func (c *LRU) Insert(key string, value interface{}, height int64) *entry {
+func (l *LRU) Insert(key string, value interface{}, height int64) {
+	c := l.cache
 	c.Lock()
 	defer c.Unlock()
-	// Check for existing item
-	if item, ok := c.items[key]; ok {
-		if value != nil {
-			item.value = value
-			if item.height < height {
-				c.tree.Delete(item)
-				item.height = height
-				c.tree.ReplaceOrInsert(item)
-			}
-			if item.cond != nil {
-				item.cond.Broadcast()
-			}
+	item, ok := l.items[key]
+	if !ok {
+		item = &entry{key: key, lru: l, height: height, done: make(chan struct{})}
+		l.add(item)
+	} else if item.height < height {
+		l.tree.Delete(item)
+		item.height = height
+		l.tree.ReplaceOrInsert(item)
+	}
+	l.fill(item, value)
+	if item.state == Sucess {
+		// a fetch of the key still running keeps its result to itself.
+		select {
+		case <-item.done:
+		default:
+			close(item.done)
 		}
-		return item
 	}
+}
 
-	// Add new item
-	entry := &entry{key: key, height: height}
-	if value == nil {
-		entry.state = UnInited
-		entry.cond = sync.NewCond(&sync.Mutex{})
-	} else {
-		entry.state = Sucess
-		entry.value = value
-	}
-	c.items[key] = entry
-	c.tree.ReplaceOrInsert(entry)
-	if c.tree.Len() > int(c.maxSize) {
-		item, ok := c.tree.DeleteMin()
-		if ok {
-			delete(c.items, item.key)
-		}
+// Peek returns the value of key if it is cached, without fetching it.
+func (l *LRU) Peek(key string) (value interface{}, ok bool) {
+	c := l.cache
+	c.Lock()
+	defer c.Unlock()
+	item, ok := l.items[key]
+	if !ok || item.state != Sucess {
+		c.metrics.IncreaseMisses(c.name)
+		return nil, false
 	}
-	return entry
+	c.recency.MoveToFront(item.elem)
+	c.metrics.IncreaseHits(c.name)
+	return item.value, true
 }
 
-// Get looks up a key's value from the cache.
-func (c *LRU) Get(key string, height int64, fetchFn FetchFn) (value interface{}, err error) {
-	c.RLock()
-	if item, ok := c.items[key]; ok {
-		c.RUnlock()
-		return item.Value(fetchFn)
+// Get looks up a key's value from the cache, fetching it once if missing.
+// Concurrent lookups of a missing key wait for a single fetch, a failed fetch
+// is not cached so the next lookup retries it.
+func (l *LRU) Get(key string, height int64, fetchFn FetchFn) (value interface{}, err error) {
+	c := l.cache
+	c.Lock()
+	item, ok := l.items[key]
+	if ok && item.state == Sucess {
+		c.recency.MoveToFront(item.elem)
+		value = item.value
+		c.Unlock()
+		c.metrics.IncreaseHits(c.name)
+		return value, nil
+	}
+	if ok {
+		c.Unlock()
+		<-item.done
+		c.Lock()
+		defer c.Unlock()
+		// a lookup waiting on the fetch of another one is not a hit.
+		if item.state == Sucess {
+			c.metrics.IncreaseCoalesced(c.name)
+			return item.value, nil
+		}
+		c.metrics.IncreaseMisses(c.name)
+		return nil, item.err
+	}
+	item = &entry{key: key, lru: l, height: height, state: InProcess, done: make(chan struct{})}
+	l.add(item)
+	c.Unlock()
+	c.metrics.IncreaseMisses(c.name)
+
+	value, err = fetchFn()
+	c.Lock()
+	defer c.Unlock()
+	if item.state == Sucess {
+		// Insert filled the item meanwhile.
+		return item.value, nil
+	}
+	if err != nil {
+		item.state = Failed
+		item.err = err
+		if l.items[key] == item {
+			l.remove(item)
+			c.metrics.IncreaseEvictions(c.name, "failed")
+		}
+	} else if l.items[key] == item {
+		l.fill(item, value)
 	} else {
-		c.RUnlock()
-		item := c.Insert(key, nil, height)
-		return item.Value(fetchFn)
+		item.value = value
+		item.state = Sucess
 	}
+	close(item.done)
+	return value, err
 }
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	cache := NewCache("test", 3, 10)
+	a := cache.GetOrCreatePrefixCache("a")
+	b := cache.GetOrCreatePrefixCache("b")
+
+	// the lowest height is evicted beyond the size of a prefix.
+	for i := 0; i < 4; i++ {
+		a.Insert(strconv.Itoa(i), []byte{byte(i)}, int64(i))
+	}
+	fetched := false
+	_, err := a.Get("0", 0, func() (interface{}, error) {
+		fetched = true
+		return []byte{0}, nil
+	})
+	require.NoError(t, err)
+	require.True(t, fetched)
+	require.Equal(t, int64(3), cache.Bytes())
+
+	// the least recently used value is evicted beyond the memory budget.
+	value, err := a.Get("1", 1, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, value)
+	b.Insert("big", make([]byte, 8), 0)
+	require.Equal(t, int64(10), cache.Bytes())
+	_, err = a.Get("1", 1, nil)
+	require.NoError(t, err)
+
+	// a failed fetch is retried.
+	fail := errors.New("fail")
+	_, err = b.Get("x", 0, func() (interface{}, error) { return nil, fail })
+	require.ErrorIs(t, err, fail)
+	value, err = b.Get("x", 0, func() (interface{}, error) { return []byte{1}, nil })
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, value)
+}
+
+// counterValue returns the value of the counter name of cache.
+func counterValue(t *testing.T, name, cache string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "cache" && label.GetValue() == cache {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestCacheSingleFlight(t *testing.T) {
+	lru := NewCache("single-flight", 0, 0).GetOrCreatePrefixCache("a")
+	var fetches int32
+	release := make(chan struct{})
+	fail := errors.New("fail")
+	var err error
+	fetch := func() (interface{}, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return []byte("value"), err
+	}
+	get := func(key string) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, getErr := lru.Get(key, 0, fetch)
+				if err != nil {
+					require.ErrorIs(t, getErr, fail)
+					return
+				}
+				require.NoError(t, getErr)
+				require.Equal(t, []byte("value"), value)
+			}()
+		}
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&fetches) == 1 }, time.Second, time.Millisecond)
+		// the lookups waiting on the fetch.
+		time.Sleep(10 * time.Millisecond)
+		release <- struct{}{}
+		wg.Wait()
+		require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+		atomic.StoreInt32(&fetches, 0)
+	}
+
+	// lookups served by the fetch of another one are counted apart from hits.
+	get("key")
+	require.Equal(t, float64(0), counterValue(t, "cache_hits", "single-flight"))
+	require.Equal(t, float64(1), counterValue(t, "cache_misses", "single-flight"))
+	require.Equal(t, float64(7), counterValue(t, "cache_coalesced", "single-flight"))
+
+	// the lookups waiting on a failed fetch missed.
+	err = fail
+	get("failed")
+	require.Equal(t, float64(0), counterValue(t, "cache_hits", "single-flight"))
+	require.Equal(t, float64(9), counterValue(t, "cache_misses", "single-flight"))
+	require.Equal(t, float64(7), counterValue(t, "cache_coalesced", "single-flight"))
+}
@@ -23,6 +23,59 @@ func CommonPrefix(env, chainId, role string, blockTy pb.BlockInfo_BlockType) str
 	}
 }
 
+// SnapshotPrefix is the prefix of the DB snapshots of a chain.
+func SnapshotPrefix(env, chainId, role string) string {
+	return fmt.Sprintf("%s/snapshot", TopicPrefix(env, chainId, role))
+}
+
+// SnapshotLatestKey is the key of the file holding the manifest key of the latest snapshot.
+func SnapshotLatestKey(env, chainId, role string) string {
+	return fmt.Sprintf("%s/latest", SnapshotPrefix(env, chainId, role))
+}
+
+func SnapshotManifestKey(env, chainId, role string, blockNum int64) string {
+	return fmt.Sprintf("%s/%012d/manifest", SnapshotPrefix(env, chainId, role), blockNum)
+}
+
+func SnapshotChunkKey(env, chainId, role string, blockNum int64, dbID int32, index int) string {
+	return fmt.Sprintf("%s/%012d/%d/%06d", SnapshotPrefix(env, chainId, role), blockNum, dbID, index)
+}
+
+// HeaderIndexKey is the key of the header index segment starting at height start.
+func HeaderIndexKey(env, chainId, role string, start int64) string {
+	return fmt.Sprintf("%s/index/header/%012d", TopicPrefix(env, chainId, role), start)
+}
+
+// HeaderIndexHeadKey is the key of the file holding the start of the highest
+// header index segment.
+func HeaderIndexHeadKey(env, chainId, role string) string {
+	return fmt.Sprintf("%s/index/header/head", TopicPrefix(env, chainId, role))
+}
+
+// IsHeaderIndexKey reports whether key is a header index segment or head file.
+func IsHeaderIndexKey(key string) bool {
+	keys := strings.Split(key, "/")
+	return len(keys) == 6 && keys[3] == "index" && keys[4] == "header"
+}
+
+// CheckpointPrefix is the prefix of the named checkpoints of a chain.
+func CheckpointPrefix(env, chainId, role, name string) string {
+	return fmt.Sprintf("%s/checkpoint/%s", TopicPrefix(env, chainId, role), name)
+}
+
+// CheckpointLatestKey is the key of the file holding the manifest key of the latest checkpoint.
+func CheckpointLatestKey(prefix string) string {
+	return fmt.Sprintf("%s/latest", prefix)
+}
+
+func CheckpointManifestKey(prefix string, seq int64) string {
+	return fmt.Sprintf("%s/%020d/manifest", prefix, seq)
+}
+
+func CheckpointChunkKey(prefix string, seq int64, dbID int32, index int) string {
+	return fmt.Sprintf("%s/%020d/%d/%06d", prefix, seq, dbID, index)
+}
+
 func Topic(env, chainId, role string) string {
 	return fmt.Sprintf("%s-%s-%s-header", env, chainId, role)
 }
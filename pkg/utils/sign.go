@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+)
+
+// signDomain keeps block signatures from being valid for anything else signed
+// by a writer key.
+const signDomain = "nodex-block-v1"
+
+// ItemsDigest returns the sha256 of batch items, it does not depend on how
+// the items are marshalled, compressed or stored.
+func ItemsDigest(items []*pb.Data) []byte {
+	h := sha256.New()
+	var buf [12]byte
+	for _, item := range items {
+		binary.BigEndian.PutUint32(buf[:4], uint32(item.Id))
+		binary.BigEndian.PutUint64(buf[4:], uint64(len(item.Data)))
+		h.Write(buf[:])
+		h.Write(item.Data)
+	}
+	return h.Sum(nil)
+}
+
+// BlockDigest returns the content digest of a block signed by the writer from
+// the ItemsDigest of its data and header items.
+func BlockDigest(dataDigest, headerDigest []byte) []byte {
+	sum := sha256.Sum256(append(append([]byte{}, dataDigest...), headerDigest...))
+	return sum[:]
+}
+
+// signedMessage is what a block signature signs, the fields naming the block
+// and its content digest.
+func signedMessage(info *pb.BlockInfo, digest []byte) []byte {
+	var buf bytes.Buffer
+	writeField := func(field []byte) {
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(field)))
+		buf.Write(size[:])
+		buf.Write(field)
+	}
+	writeInt := func(v int64) {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(v))
+		buf.Write(b[:])
+	}
+	writeField([]byte(signDomain))
+	writeField([]byte(info.ChainId))
+	writeField([]byte(info.Env))
+	writeField([]byte(info.Role))
+	writeInt(info.BlockNum)
+	writeField([]byte(info.BlockHash))
+	writeField([]byte(info.BlockRoot))
+	writeInt(info.MsgOffset)
+	writeField(digest)
+	return buf.Bytes()
+}
+
+// SignBlock sets the signature of info over its block of content digest.
+func SignBlock(key ed25519.PrivateKey, info *pb.BlockInfo, digest []byte) {
+	info.Signature = ed25519.Sign(key, signedMessage(info, digest))
+}
+
+// VerifyBlock checks the signature of info over its block of content digest
+// was made by one of keys.
+func VerifyBlock(keys []ed25519.PublicKey, info *pb.BlockInfo, digest []byte) error {
+	if len(info.Signature) == 0 {
+		return fmt.Errorf("%w: block %d %s is unsigned", ErrBadSignature, info.BlockNum, info.BlockHash)
+	}
+	msg := signedMessage(info, digest)
+	for _, key := range keys {
+		if ed25519.Verify(key, msg, info.Signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: block %d %s", ErrBadSignature, info.BlockNum, info.BlockHash)
+}
+
+// LoadSigningKey reads a base64 ed25519 private key, or its seed, from path.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", path, err)
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("signing key %s: invalid size %d", path, len(key))
+	}
+}
+
+// ParsePublicKeys parses comma separated base64 ed25519 public keys.
+func ParsePublicKeys(s string) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, k := range strings.Split(s, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(k)
+		if err != nil {
+			return nil, fmt.Errorf("public key %s: %w", k, err)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("public key %s: invalid size %d", k, len(key))
+		}
+		keys = append(keys, ed25519.PublicKey(key))
+	}
+	return keys, nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignBlock(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(priv.Seed())+"\n"), 0o600))
+	key, err := LoadSigningKey(path)
+	require.NoError(t, err)
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys, err := ParsePublicKeys(base64.StdEncoding.EncodeToString(other) + "," + base64.StdEncoding.EncodeToString(pub))
+	require.NoError(t, err)
+
+	info := &pb.BlockInfo{Env: "test", ChainId: "256", Role: "master", BlockNum: 1, BlockHash: "1", MsgOffset: 1}
+	data := []*pb.Data{{Id: 0, Data: []byte("data")}}
+	header := []*pb.Data{{Id: 1, Data: []byte("header")}}
+	require.ErrorIs(t, VerifyBlock(keys, info, BlockDigest(ItemsDigest(data), ItemsDigest(header))), ErrBadSignature)
+	SignBlock(key, info, BlockDigest(ItemsDigest(data), ItemsDigest(header)))
+	require.NoError(t, VerifyBlock(keys, info, BlockDigest(ItemsDigest(data), ItemsDigest(header))))
+
+	// a changed item, a moved item or a changed info does not verify.
+	tampered := []*pb.Data{{Id: 0, Data: []byte("dat")}}
+	require.ErrorIs(t, VerifyBlock(keys, info, BlockDigest(ItemsDigest(tampered), ItemsDigest(header))), ErrBadSignature)
+	require.ErrorIs(t, VerifyBlock(keys, info, BlockDigest(ItemsDigest(nil), ItemsDigest(append(data, header...)))), ErrBadSignature)
+	info.MsgOffset = 2
+	require.ErrorIs(t, VerifyBlock(keys, info, BlockDigest(ItemsDigest(data), ItemsDigest(header))), ErrBadSignature)
+	info.MsgOffset = 1
+	require.ErrorIs(t, VerifyBlock(keys[:1], info, BlockDigest(ItemsDigest(data), ItemsDigest(header))), ErrBadSignature)
+}
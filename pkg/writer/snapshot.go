@@ -0,0 +1,100 @@
+package reader
+
+import (
+	"context"
+	"time"
+
+	"github.com/DeBankDeFi/nodex/pkg/db"
+	"github.com/DeBankDeFi/nodex/pkg/pb"
+	"github.com/DeBankDeFi/nodex/pkg/s3"
+	"github.com/DeBankDeFi/nodex/pkg/utils"
+	"github.com/avast/retry-go/v4"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// ExportSnapshot uploads the DBs of ps in chunks, then its manifest, and
+// finally points the latest snapshot of the chain to the manifest so a
+// partially uploaded snapshot is never used.
+func ExportSnapshot(ctx context.Context, client *s3.Client, env, chainId, role string, ps *db.PoolSnapshot) (*pb.Snapshot, error) {
+	blockNum := ps.Info.BlockNum
+	manifestKey := utils.SnapshotManifestKey(env, chainId, role, blockNum)
+	manifest := &pb.Snapshot{
+		Key:        manifestKey,
+		Info:       ps.Info,
+		CreateTime: time.Now().Unix(),
+	}
+	for _, dbInfo := range ps.DBs {
+		snapshotDB := &pb.SnapshotDB{DbInfo: dbInfo}
+		err := ps.Export(dbInfo.Id, db.SnapshotChunkSize, func(chunk []byte) error {
+			key := utils.SnapshotChunkKey(env, chainId, role, blockNum, dbInfo.Id, len(snapshotDB.Chunks))
+			var stored *pb.SnapshotChunk
+			err := retry.Do(
+				func() (err error) {
+					stored, err = client.PutCompressedFile(ctx, key, chunk)
+					return err
+				},
+				retry.Attempts(10),
+				retry.Delay(5*time.Second),
+				retry.LastErrorOnly(true),
+			)
+			if err != nil {
+				return err
+			}
+			snapshotDB.Chunks = append(snapshotDB.Chunks, stored)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		manifest.Dbs = append(manifest.Dbs, snapshotDB)
+	}
+	buf, err := proto.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	err = client.PutFile(ctx, manifestKey, buf)
+	if err != nil {
+		return nil, err
+	}
+	err = client.PutFile(ctx, utils.SnapshotLatestKey(env, chainId, role), []byte(manifestKey))
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// snapshot starts the export of a snapshot of the DBs if info is at a
+// snapshot interval and no export is running, w must be locked and info
+// just applied so the snapshot is consistent.
+func (w *Writer) snapshot(info *pb.BlockInfo) {
+	if w.config.SnapshotInterval <= 0 || info.BlockNum%int64(w.config.SnapshotInterval) != 0 || w.snapshotting {
+		return
+	}
+	ps, err := w.dbPool.Snapshot()
+	if err != nil {
+		utils.Logger().Error("Snapshot error", zap.Error(err))
+		return
+	}
+	if ps.Info == nil {
+		ps.Release()
+		return
+	}
+	w.snapshotting = true
+	go func() {
+		defer func() {
+			ps.Release()
+			w.Lock()
+			w.snapshotting = false
+			w.Unlock()
+		}()
+		startTime := time.Now()
+		_, err := ExportSnapshot(context.Background(), w.s3, w.config.Env, w.config.ChainId, w.config.Role, ps)
+		if err != nil {
+			utils.Logger().Error("ExportSnapshot error", zap.Error(err), zap.Int64("BlockNum", ps.Info.BlockNum))
+			return
+		}
+		utils.Logger().Info("ExportSnapshot success", zap.Int64("BlockNum", ps.Info.BlockNum),
+			zap.Duration("duration", time.Since(startTime)))
+	}()
+}
@@ -2,6 +2,8 @@ package reader
 
 import (
 	"context"
+	"crypto/ed25519"
+	"fmt"
 	"runtime"
 	"sync"
 	"time"
@@ -25,24 +27,38 @@ type Writer struct {
 
 	dbPool *db.DBPool
 	s3     *s3.Client
-	kafka  *kafka.KafkaClient
+	bus    kafka.HeaderBus
 
 	lastBlockHeader *pb.BlockInfo
 
+	// signingKey signs the blocks if set, dataDigest is the ItemsDigest of the
+	// data block of msg offset dataDigestOffset put to s3.
+	signingKey       ed25519.PrivateKey
+	dataDigest       []byte
+	dataDigestOffset int64
+
 	stop bool
+	// snapshotting is true while a snapshot of the DBs is exported.
+	snapshotting bool
 }
 
 // NewWriter creates a new writer.
 func NewWriter(config *utils.Config, dbPool *db.DBPool) (writer *Writer, err error) {
-	s3, err := s3.NewClient(config.S3ProxyAddr)
+	s3Client, err := s3.NewClient(config.S3ProxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := s3.ParseCompression(config.Compression)
 	if err != nil {
 		return nil, err
 	}
+	s3Client.SetCompression(compression)
+	s3Client.SetReplicas(config.S3ProxyReplicas)
 
 	topic := utils.Topic(config.Env, config.ChainId, config.Role)
 
 	// golang max value of int64
-	kafka, err := kafka.NewKafkaClient(topic, 1<<63-1, config.KafkaAddr)
+	bus, err := kafka.NewHeaderBus(config, topic, 1<<63-1)
 	if err != nil {
 		return nil, err
 	}
@@ -57,12 +73,21 @@ func NewWriter(config *utils.Config, dbPool *db.DBPool) (writer *Writer, err err
 
 	utils.Logger().Info("NewWriter", zap.Any("lastBlockHeader", lastBlockHeader))
 
+	var signingKey ed25519.PrivateKey
+	if config.SigningKey != "" {
+		signingKey, err = utils.LoadSigningKey(config.SigningKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	writer = &Writer{
 		config:          config,
 		dbPool:          dbPool,
-		s3:              s3,
-		kafka:           kafka,
+		s3:              s3Client,
+		bus:             bus,
 		lastBlockHeader: lastBlockHeader,
+		signingKey:      signingKey,
 	}
 
 	return writer, nil
@@ -73,27 +98,28 @@ func (w *Writer) Recovery() error {
 	w.Lock()
 	defer w.Unlock()
 	if w.lastBlockHeader.MsgOffset == -1 && w.lastBlockHeader.BlockNum != -1 {
-		infos, err := w.s3.ListHeaderStartAt(context.Background(), w.config.ChainId, w.config.Env, w.config.Role,
-			w.lastBlockHeader.BlockNum-1, 5, -1)
+		last := w.lastBlockHeader
+		err := w.s3.ListHeaders(context.Background(), w.config.ChainId, w.config.Env, w.config.Role,
+			last.BlockNum-1, last.BlockNum+2, -1, func(info *pb.BlockInfo) bool {
+				if info.BlockHash == last.BlockHash || info.BlockNum == last.BlockNum {
+					w.lastBlockHeader = info
+					return false
+				}
+				if info.BlockNum == last.BlockNum+1 {
+					w.lastBlockHeader.MsgOffset = info.MsgOffset - 1
+					return false
+				}
+				return true
+			})
 		if err != nil {
-			utils.Logger().Error("ListHeaderStartAt error", zap.Error(err))
+			utils.Logger().Error("ListHeaders error", zap.Error(err))
 			return err
 		}
-		for _, info := range infos {
-			if info.BlockHash == w.lastBlockHeader.BlockHash || info.BlockNum == w.lastBlockHeader.BlockNum {
-				w.lastBlockHeader = info
-				break
-			}
-			if info.BlockNum == w.lastBlockHeader.BlockNum+1 {
-				w.lastBlockHeader.MsgOffset = info.MsgOffset - 1
-				break
-			}
-		}
 	}
 	startWriteOffset := w.lastBlockHeader.MsgOffset + 1
-	lastWriteOffset := w.kafka.LastWriterOffset()
+	lastWriteOffset := w.bus.LastWriterOffset()
 	for startWriteOffset <= lastWriteOffset {
-		infos, err := w.kafka.FetchStart(context.Background(), startWriteOffset)
+		infos, err := w.bus.FetchStart(context.Background(), startWriteOffset)
 		if err != nil {
 			return err
 		}
@@ -110,11 +136,12 @@ func (w *Writer) Recovery() error {
 				return err
 			}
 			blockFile, err := w.s3.GetBlock(context.Background(), &pb.BlockInfo{
-				ChainId:   w.config.ChainId,
-				Env:       w.config.Env,
-				Role:      w.config.Role,
-				BlockHash: headerFile.Info.BlockHash,
-				BlockType: pb.BlockInfo_DATA,
+				ChainId:      w.config.ChainId,
+				Env:          w.config.Env,
+				Role:         w.config.Role,
+				BlockHash:    headerFile.Info.BlockHash,
+				BlockType:    pb.BlockInfo_DATA,
+				DataChecksum: headerFile.Info.DataChecksum,
 			}, false)
 			if err != nil {
 				return err
@@ -144,7 +171,7 @@ func (w *Writer) Recovery() error {
 		if lastWriteOffset != w.lastBlockHeader.MsgOffset-1 {
 			return utils.ErrWriterRecovey
 		}
-		err := w.kafka.Broadcast(context.Background(), w.lastBlockHeader)
+		err := w.bus.Broadcast(context.Background(), w.lastBlockHeader)
 		if err != nil {
 			return err
 		}
@@ -188,9 +215,11 @@ func (w *Writer) WriteBlockToS3(info *pb.BlockInfo, batchs []db.BatchWithID) (er
 	block.Info.BlockType = pb.BlockInfo_DATA
 	block.Info.BlockSize = int64(proto.Size(block))
 	// commit to s3.
+	var stored *pb.BlockInfo
 	err = retry.Do(
-		func() error {
-			return w.s3.PutBlock(context.Background(), block)
+		func() (err error) {
+			stored, err = w.s3.PutBlockInfo(context.Background(), block)
+			return err
 		},
 		retry.Attempts(10),
 		retry.Delay(5*time.Second),
@@ -200,9 +229,32 @@ func (w *Writer) WriteBlockToS3(info *pb.BlockInfo, batchs []db.BatchWithID) (er
 		utils.Logger().Error("WriteBlockToS3", zap.Error(err))
 		return err
 	}
+	// the header of the block carries the checksum of its data.
+	info.Compression = stored.Compression
+	info.DataChecksum = stored.DataChecksum
+	info.BlockSize = stored.BlockSize
+	if w.signingKey != nil {
+		w.dataDigest = utils.ItemsDigest(batchItems)
+		w.dataDigestOffset = info.MsgOffset
+	}
 	return nil
 }
 
+// blockDataDigest returns the ItemsDigest of the data block of info, read back
+// from s3 unless it is the last one put by the writer.
+func (w *Writer) blockDataDigest(info *pb.BlockInfo) ([]byte, error) {
+	if w.dataDigest != nil && w.dataDigestOffset == info.MsgOffset {
+		return w.dataDigest, nil
+	}
+	dataInfo := proto.Clone(info).(*pb.BlockInfo)
+	dataInfo.BlockType = pb.BlockInfo_DATA
+	block, err := w.s3.GetBlock(context.Background(), dataInfo, true)
+	if err != nil {
+		return nil, fmt.Errorf("data block %d %s of the header to sign: %w", info.BlockNum, info.BlockHash, err)
+	}
+	return utils.ItemsDigest(block.BatchItems), nil
+}
+
 func (w *Writer) WriteBlockToDB(batchs []db.BatchWithID) (err error) {
 	w.Lock()
 	defer w.Unlock()
@@ -231,10 +283,20 @@ func (w *Writer) WriteBlockHeaderToS3(info *pb.BlockInfo, batchs []db.BatchWithI
 		BatchItems: batchItems,
 	}
 	blockHeader.Info.BlockType = pb.BlockInfo_HEADER
+	if w.signingKey != nil {
+		// the header object and the header broadcast carry the signature.
+		dataDigest, err := w.blockDataDigest(info)
+		if err != nil {
+			return err
+		}
+		utils.SignBlock(w.signingKey, info, utils.BlockDigest(dataDigest, utils.ItemsDigest(batchItems)))
+	}
 	// commit to s3.
+	var stored *pb.BlockInfo
 	err = retry.Do(
-		func() error {
-			return w.s3.PutBlock(context.Background(), blockHeader)
+		func() (err error) {
+			stored, err = w.s3.PutBlockInfo(context.Background(), blockHeader)
+			return err
 		},
 		retry.Attempts(10),
 		retry.Delay(5*time.Second),
@@ -243,6 +305,10 @@ func (w *Writer) WriteBlockHeaderToS3(info *pb.BlockInfo, batchs []db.BatchWithI
 	if err != nil {
 		return err
 	}
+	// the header broadcast carries the checksum of the header object.
+	info.Compression = stored.Compression
+	info.HeaderChecksum = stored.HeaderChecksum
+	info.BlockSize = stored.BlockSize
 	utils.Logger().Debug("WriteBlockHeaderToS3", zap.Any(".Info", blockHeader.Info))
 	return nil
 }
@@ -262,6 +328,7 @@ func (w *Writer) WriteBlockHeaderToDB(info *pb.BlockInfo, batchs []db.BatchWithI
 		return err
 	}
 	w.lastBlockHeader = info
+	w.snapshot(info)
 	return nil
 }
 
@@ -274,14 +341,14 @@ func (w *Writer) WriteBlockHeaderToKafka() (err error) {
 	}
 	retry.Do(
 		func() error {
-			_, lastOffset, err := w.kafka.RemoteOffset()
+			_, lastOffset, err := w.bus.RemoteOffset()
 			if err != nil {
 				return err
 			}
 			if lastOffset == w.lastBlockHeader.MsgOffset {
 				return nil
 			}
-			err = w.kafka.Broadcast(context.Background(), w.lastBlockHeader)
+			err = w.bus.Broadcast(context.Background(), w.lastBlockHeader)
 			if err != nil {
 				return err
 			}